@@ -0,0 +1,678 @@
+/**
+ * @file accounts.go
+ * @description プレイヤーアカウント・認証・プロフィール
+ *
+ * 概要:
+ * - ユーザー名とパスワードで登録・ログインする（パスワードはbcryptでハッシュ化して保存）
+ * - ログインするとセッショントークンを発行し、REST APIでは Authorization: Bearer ヘッダーで検証する
+ * - /ws への接続には、トークンと引き換えに発行した短時間・1回限りのチケットを ?ticket= で渡す
+ *   （URLはアクセスログに残るため、長期間有効なトークンは載せない）
+ * - プロフィール（表示名・色・成績・解放要素）を保存し、ゲームへの参加時に反映する
+ * - トークンなしの接続は従来通りのゲストとして扱う。ゲストにはサーバーが署名したIDを Cookie で発行し、
+ *   キック・ミュートの対象の識別に使う（接続元のIPは偽装でき、同じNAT内のゲストを区別できないため使わない）
+ * - 保存先は AccountStore インターフェースで差し替えられる（標準はJSONファイル）
+ */
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// アカウントの保存先ファイル（環境変数 ACCOUNTS_FILE で変更、空なら保存しない）
+	defaultAccountsFile = "data/accounts.json"
+	// セッションの有効期間
+	sessionLifetime = 7 * 24 * time.Hour
+	// WebSocket接続用チケットの有効期間
+	wsTicketLifetime = 30 * time.Second
+	// ゲストIDの Cookie 名と有効期間
+	guestCookieName     = "guest"
+	guestCookieLifetime = 365 * 24 * time.Hour
+	// パスワードの長さの範囲（bcryptは72バイトまで）
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+var (
+	// ユーザー名の形式（英数字とアンダースコア、3〜20文字）
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,20}$`)
+
+	errAccountExists   = errors.New("このユーザー名は既に使われています")
+	errAccountNotFound = errors.New("アカウントが見つかりません")
+)
+
+/**
+ * プレイヤーの通算成績構造体
+ * @property {int} GamesPlayed - プレイ回数（ゲームオーバー・クリアまで遊んだ回数）
+ * @property {int} Clears - クリア回数
+ * @property {int} BestScore - 最高スコア
+ * @property {int} TotalScore - 累計スコア
+ * @property {int} HighestLevel - 到達した最高レベル
+ */
+type ProfileStats struct {
+	GamesPlayed  int `json:"gamesPlayed"`
+	Clears       int `json:"clears"`
+	BestScore    int `json:"bestScore"`
+	TotalScore   int `json:"totalScore"`
+	HighestLevel int `json:"highestLevel"`
+}
+
+/**
+ * プロフィール構造体
+ * @property {string} DisplayName - 表示名（空ならユーザー名）
+ * @property {string} Color - 機体の色（空ならランダム）
+ * @property {ProfileStats} Stats - 通算成績
+ * @property {[]string} Unlocks - 解放済みの要素（"clear:hard" など）
+ */
+type Profile struct {
+	DisplayName string       `json:"displayName"`
+	Color       string       `json:"color"`
+	Stats       ProfileStats `json:"stats"`
+	Unlocks     []string     `json:"unlocks"`
+}
+
+/**
+ * アカウント構造体
+ * @property {string} Username - ユーザー名
+ * @property {[]byte} PasswordHash - bcryptでハッシュ化したパスワード
+ * @property {time.Time} CreatedAt - 登録日時
+ * @property {Profile} Profile - プロフィール
+ */
+type Account struct {
+	Username     string    `json:"username"`
+	PasswordHash []byte    `json:"passwordHash"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Profile      Profile   `json:"profile"`
+}
+
+/**
+ * アカウントの保存先インターフェース
+ * ユーザー名は大文字・小文字を区別しない。実装は複数のゴルーチンから同時に呼ばれても安全であること
+ */
+type AccountStore interface {
+	// アカウントを追加する（既にあれば errAccountExists）
+	Create(account Account) error
+	// アカウントのコピーを取得する（なければ errAccountNotFound）
+	Get(username string) (Account, error)
+	// アカウントを書き換えて保存する（なければ errAccountNotFound）
+	Update(username string, update func(account *Account)) error
+}
+
+// 使用中のアカウントの保存先（main で初期化する）
+var accounts AccountStore = newFileAccounts("")
+
+/**
+ * JSONファイルに保存するアカウント
+ * @property {string} path - 保存先ファイル（空なら保存しない）
+ * @property {sync.Mutex} mutex - 同時アクセス防止のミューテックス
+ * @property {map[string]*Account} accounts - アカウント（キー：小文字のユーザー名）
+ */
+type fileAccounts struct {
+	path     string
+	mutex    sync.Mutex
+	accounts map[string]*Account
+}
+
+/**
+ * JSONファイルのアカウントを作成する
+ * @param {string} path - 保存先ファイル（空ならメモリのみ）
+ * @returns {*fileAccounts} - 作成された保存先
+ */
+func newFileAccounts(path string) *fileAccounts {
+	return &fileAccounts{path: path, accounts: make(map[string]*Account)}
+}
+
+/**
+ * JSONファイルのアカウントを開く
+ * ファイルがなければ空の状態で始める
+ * @param {string} path - 保存先ファイル
+ * @returns {*fileAccounts, error} - 保存先と読み込みエラー
+ */
+func openFileAccounts(path string) (*fileAccounts, error) {
+	store := newFileAccounts(path)
+	if path == "" {
+		return store, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.accounts); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+/**
+ * アカウントを追加する
+ * @param {Account} account - アカウント
+ * @returns {error} - 既に存在する場合や保存エラー
+ */
+func (s *fileAccounts) Create(account Account) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := strings.ToLower(account.Username)
+	if _, ok := s.accounts[key]; ok {
+		return errAccountExists
+	}
+	s.accounts[key] = &account
+	return s.save()
+}
+
+/**
+ * アカウントのコピーを取得する
+ * @param {string} username - ユーザー名
+ * @returns {Account, error} - アカウント
+ */
+func (s *fileAccounts) Get(username string) (Account, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	a, ok := s.accounts[strings.ToLower(username)]
+	if !ok {
+		return Account{}, errAccountNotFound
+	}
+	copied := *a
+	copied.Profile.Unlocks = append([]string(nil), a.Profile.Unlocks...)
+	return copied, nil
+}
+
+/**
+ * アカウントを書き換えて保存する
+ * @param {string} username - ユーザー名
+ * @param {func(*Account)} update - 書き換え処理（ロック中に呼ばれる）
+ * @returns {error} - 見つからない場合や保存エラー
+ */
+func (s *fileAccounts) Update(username string, update func(account *Account)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	a, ok := s.accounts[strings.ToLower(username)]
+	if !ok {
+		return errAccountNotFound
+	}
+	update(a)
+	return s.save()
+}
+
+/**
+ * アカウントをファイルに書き出す（一時ファイルに書いてから置き換える）
+ * @returns {error} - 書き込みエラー（あれば）
+ */
+func (s *fileAccounts) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.accounts)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+/**
+ * セッション構造体
+ * @property {string} Username - ログイン中のユーザー名
+ * @property {time.Time} Expires - 有効期限
+ */
+type session struct {
+	Username string
+	Expires  time.Time
+}
+
+var (
+	// ログイン中のセッション（キー：トークン、サーバー再起動で失効）
+	sessions = make(map[string]session)
+	// 未使用のWebSocket接続用チケット（キー：チケット、値の Expires はチケットの期限）
+	wsTickets = make(map[string]session)
+	// セッション・チケットへの同時アクセスを防ぐためのミューテックス
+	sessionsMutex sync.Mutex
+)
+
+/**
+ * ランダムなトークンを生成する
+ * @returns {string} - 32バイトの16進数文字列
+ */
+func randomToken() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ゲストIDの署名鍵（起動ごとに生成するため、再起動後は新しいIDを発行し直す）
+var guestKey = []byte(randomToken())
+
+/**
+ * ゲストIDの署名を求める
+ * @param {string} id - ゲストID
+ * @returns {string} - HMAC-SHA256の16進数文字列
+ */
+func signGuestID(id string) string {
+	mac := hmac.New(sha256.New, guestKey)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+ * リクエストの Cookie からゲストIDを取得する
+ * 署名の正しいIDがなければ新しく発行し、設定する Cookie を返す
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {string, *http.Cookie} - ゲストIDと、新しく発行した場合の Cookie（既存のIDならnil）
+ */
+func guestIdentity(c echo.Context) (string, *http.Cookie) {
+	if cookie, err := c.Cookie(guestCookieName); err == nil {
+		if id, sig, ok := strings.Cut(cookie.Value, "."); ok && hmac.Equal([]byte(sig), []byte(signGuestID(id))) {
+			return id, nil
+		}
+	}
+	id := randomToken()
+	return id, &http.Cookie{
+		Name:     guestCookieName,
+		Value:    id + "." + signGuestID(id),
+		Path:     "/",
+		Expires:  time.Now().Add(guestCookieLifetime),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+/**
+ * セッションを発行する
+ * @param {string} username - ユーザー名
+ * @returns {string} - セッショントークン
+ */
+func createSession(username string) string {
+	token := randomToken()
+
+	sessionsMutex.Lock()
+	sessions[token] = session{Username: username, Expires: time.Now().Add(sessionLifetime)}
+	sessionsMutex.Unlock()
+	return token
+}
+
+/**
+ * セッショントークンを検証する
+ * @param {string} token - セッショントークン
+ * @returns {string, bool} - ユーザー名と有効かどうか（期限切れのセッションは削除する）
+ */
+func sessionUser(token string) (string, bool) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+
+	s, ok := sessions[token]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(s.Expires) {
+		delete(sessions, token)
+		return "", false
+	}
+	return s.Username, true
+}
+
+/**
+ * セッションを破棄する
+ * @param {string} token - セッショントークン
+ */
+func deleteSession(token string) {
+	sessionsMutex.Lock()
+	delete(sessions, token)
+	sessionsMutex.Unlock()
+}
+
+/**
+ * WebSocket接続用のチケットを発行する
+ * 期限切れのチケットもここで掃除する
+ * @param {string} username - ユーザー名
+ * @returns {string} - チケット
+ */
+func createWSTicket(username string) string {
+	ticket := randomToken()
+	now := time.Now()
+
+	sessionsMutex.Lock()
+	for t, s := range wsTickets {
+		if now.After(s.Expires) {
+			delete(wsTickets, t)
+		}
+	}
+	wsTickets[ticket] = session{Username: username, Expires: now.Add(wsTicketLifetime)}
+	sessionsMutex.Unlock()
+	return ticket
+}
+
+/**
+ * WebSocket接続用のチケットを使う（1回使うと無効になる）
+ * @param {string} ticket - チケット
+ * @returns {string, bool} - ユーザー名と有効かどうか
+ */
+func redeemWSTicket(ticket string) (string, bool) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+
+	s, ok := wsTickets[ticket]
+	if !ok {
+		return "", false
+	}
+	delete(wsTickets, ticket)
+	if time.Now().After(s.Expires) {
+		return "", false
+	}
+	return s.Username, true
+}
+
+/**
+ * リクエストのセッションからアカウントを取得する
+ * トークンは Authorization: Bearer ヘッダーから読む（URLには載せない）
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {Account, string, error} - アカウント、トークン、認証エラー
+ */
+func authenticate(c echo.Context) (Account, string, error) {
+	token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	username, ok := sessionUser(token)
+	if !ok {
+		return Account{}, "", echo.NewHTTPError(http.StatusUnauthorized, "ログインしてください")
+	}
+	account, err := accounts.Get(username)
+	if err != nil {
+		deleteSession(token)
+		return Account{}, "", echo.NewHTTPError(http.StatusUnauthorized, "ログインしてください")
+	}
+	return account, token, nil
+}
+
+/**
+ * 登録・ログインのリクエスト構造体
+ * @property {string} Username - ユーザー名
+ * @property {string} Password - パスワード
+ */
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+/**
+ * プロフィール更新のリクエスト構造体
+ * @property {*string} DisplayName - 表示名（nilなら変更しない）
+ * @property {*string} Color - 機体の色（nilなら変更しない）
+ */
+type profileUpdate struct {
+	DisplayName *string `json:"displayName"`
+	Color       *string `json:"color"`
+}
+
+// ユーザーが存在しないときも照合にかかる時間を揃えるためのハッシュ
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+/**
+ * セッションとプロフィールのレスポンスを作成する
+ * @param {Account} account - アカウント
+ * @param {string} token - セッショントークン
+ * @returns {map[string]interface{}} - レスポンス
+ */
+func sessionResponse(account Account, token string) map[string]interface{} {
+	return map[string]interface{}{
+		"token":    token,
+		"username": account.Username,
+		"profile":  account.Profile,
+	}
+}
+
+/**
+ * アカウント登録ハンドラー
+ * POST /api/register {"username": "...", "password": "..."}
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleRegister(c echo.Context) error {
+	var req credentials
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストが不正です")
+	}
+	if !usernamePattern.MatchString(req.Username) {
+		return echo.NewHTTPError(http.StatusBadRequest, "ユーザー名は英数字とアンダースコアで3〜20文字にしてください")
+	}
+	if containsBannedWord(req.Username) {
+		return echo.NewHTTPError(http.StatusBadRequest, "ユーザー名に不適切な言葉が含まれています")
+	}
+	if len(req.Password) < minPasswordLength || len(req.Password) > maxPasswordLength {
+		return echo.NewHTTPError(http.StatusBadRequest, "パスワードは8〜72バイトにしてください")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("パスワードのハッシュ化エラー:", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	account := Account{
+		Username:     req.Username,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+		Profile:      Profile{DisplayName: req.Username, Unlocks: []string{}},
+	}
+	if err := accounts.Create(account); err != nil {
+		if errors.Is(err, errAccountExists) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		log.Println("アカウントの保存エラー:", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusCreated, sessionResponse(account, createSession(account.Username)))
+}
+
+/**
+ * ログインハンドラー
+ * POST /api/login {"username": "...", "password": "..."}
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleLogin(c echo.Context) error {
+	var req credentials
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストが不正です")
+	}
+
+	account, err := accounts.Get(req.Username)
+	hash := account.PasswordHash
+	if err != nil {
+		hash = dummyPasswordHash
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) != nil || err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "ユーザー名またはパスワードが違います")
+	}
+	return c.JSON(http.StatusOK, sessionResponse(account, createSession(account.Username)))
+}
+
+/**
+ * WebSocketのチケットからアカウントを取得する
+ * @param {string} ticket - チケット
+ * @returns {Account, error} - アカウントと認証エラー
+ */
+func ticketAccount(ticket string) (Account, error) {
+	username, ok := redeemWSTicket(ticket)
+	if !ok {
+		return Account{}, echo.NewHTTPError(http.StatusUnauthorized, "接続用のチケットが無効です")
+	}
+	account, err := accounts.Get(username)
+	if err != nil {
+		return Account{}, echo.NewHTTPError(http.StatusUnauthorized, "ログインしてください")
+	}
+	return account, nil
+}
+
+/**
+ * WebSocket接続用チケットの発行ハンドラー
+ * POST /api/ws-ticket（Authorization: Bearer <token>）
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleWSTicket(c echo.Context) error {
+	account, _, err := authenticate(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"ticket": createWSTicket(account.Username)})
+}
+
+/**
+ * ログアウトハンドラー
+ * POST /api/logout（Authorization: Bearer <token>）
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleLogout(c echo.Context) error {
+	_, token, err := authenticate(c)
+	if err != nil {
+		return err
+	}
+	deleteSession(token)
+	return c.NoContent(http.StatusNoContent)
+}
+
+/**
+ * プロフィール取得ハンドラー
+ * GET /api/profile（Authorization: Bearer <token>）
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleGetProfile(c echo.Context) error {
+	account, token, err := authenticate(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sessionResponse(account, token))
+}
+
+/**
+ * プロフィール更新ハンドラー
+ * PUT /api/profile {"displayName": "...", "color": "#RRGGBB"}（Authorization: Bearer <token>）
+ * 変更は次に参加したゲームから反映される
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleUpdateProfile(c echo.Context) error {
+	account, token, err := authenticate(c)
+	if err != nil {
+		return err
+	}
+	var req profileUpdate
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストが不正です")
+	}
+	if req.DisplayName != nil {
+		name, err := validateName(*req.DisplayName)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		req.DisplayName = &name
+	}
+	if req.Color != nil && *req.Color != "" {
+		color, err := validateColor(*req.Color)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		req.Color = &color
+	}
+
+	err = accounts.Update(account.Username, func(a *Account) {
+		if req.DisplayName != nil {
+			a.Profile.DisplayName = *req.DisplayName
+		}
+		if req.Color != nil {
+			a.Profile.Color = *req.Color
+		}
+		account = *a
+	})
+	if err != nil {
+		log.Println("プロフィールの保存エラー:", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, sessionResponse(account, token))
+}
+
+/**
+ * ログイン中のプレイヤーの通算成績を更新する
+ * ゲームオーバー・クリアになったときに呼ぶ。保存はロックの外で行う
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func recordProfileStats(gameRoom *GameRoom) {
+	type result struct {
+		username string
+		score    int
+	}
+	var results []result
+	for _, p := range gameRoom.Players {
+		if p.account != "" {
+			results = append(results, result{username: p.account, score: p.Score})
+		}
+	}
+	if len(results) == 0 {
+		return
+	}
+	cleared := gameRoom.GameState == "clear"
+	level := gameRoom.Level
+	unlock := "clear:" + gameRoom.Difficulty
+
+	go func() {
+		for _, r := range results {
+			err := accounts.Update(r.username, func(a *Account) {
+				stats := &a.Profile.Stats
+				stats.GamesPlayed++
+				stats.TotalScore += r.score
+				stats.BestScore = max(stats.BestScore, r.score)
+				stats.HighestLevel = max(stats.HighestLevel, level)
+				if cleared {
+					stats.Clears++
+					addUnlock(&a.Profile, unlock)
+				}
+			})
+			if err != nil {
+				log.Println("プロフィールの保存エラー:", err, "ユーザー:", r.username)
+			}
+		}
+	}()
+}
+
+/**
+ * 解放要素を追加する（既にあれば何もしない）
+ * @param {*Profile} profile - プロフィール
+ * @param {string} unlock - 解放要素
+ */
+func addUnlock(profile *Profile, unlock string) {
+	for _, u := range profile.Unlocks {
+		if u == unlock {
+			return
+		}
+	}
+	profile.Unlocks = append(profile.Unlocks, unlock)
+}
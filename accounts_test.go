@@ -0,0 +1,63 @@
+/**
+ * @file accounts_test.go
+ * @description アカウント・認証のテスト
+ *
+ * 概要:
+ * - ゲストIDはサーバーが署名した Cookie からだけ読み取り、改ざん・ヘッダーの偽装では変えられないことを確認する
+ */
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+/**
+ * テスト用のリクエストでゲストIDを取得する
+ * @param {*http.Cookie} cookie - 送る Cookie（nilなら送らない）
+ * @param {string} forwardedFor - X-Forwarded-For ヘッダー
+ * @returns {string, *http.Cookie} - ゲストIDと、新しく発行された Cookie
+ */
+func requestGuest(cookie *http.Cookie, forwardedFor string) (string, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	if forwardedFor != "" {
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	}
+	return guestIdentity(echo.New().NewContext(req, httptest.NewRecorder()))
+}
+
+func TestGuestIdentity(t *testing.T) {
+	id, cookie := requestGuest(nil, "")
+	if cookie == nil || id == "" {
+		t.Fatal("初回の接続でゲストIDが発行されない")
+	}
+
+	// 発行された Cookie を送ればヘッダーによらず同じID
+	for _, forwardedFor := range []string{"", "203.0.113.7"} {
+		got, issued := requestGuest(cookie, forwardedFor)
+		if got != id || issued != nil {
+			t.Errorf("X-Forwarded-For %q: ID %q（発行し直し %v）, want %q", forwardedFor, got, issued != nil, id)
+		}
+	}
+
+	// 改ざんした Cookie は使わず、新しいIDを発行する
+	tampered := []string{"other." + cookie.Value[len(id)+1:], id, id + ".00"}
+	for _, value := range tampered {
+		got, issued := requestGuest(&http.Cookie{Name: guestCookieName, Value: value}, "")
+		if got == id || issued == nil {
+			t.Errorf("改ざんした Cookie %q のIDが使われた", value)
+		}
+	}
+
+	// 別のゲストには別のID
+	if other, _ := requestGuest(nil, ""); other == id {
+		t.Error("別のゲストに同じIDが発行された")
+	}
+}
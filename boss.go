@@ -0,0 +1,302 @@
+/**
+ * @file boss.go
+ * @description ボスの定義レジストリとレベル構成
+ *
+ * 概要:
+ * - 名前付きボス定義（サイズ・体力・攻撃パターン）のレジストリ
+ * - レベルごとに出現するボスの組み合わせ（双子ボスなど）
+ * - ボスの攻撃パターン実装
+ */
+
+package main
+
+import "math"
+
+/**
+ * ボス定義構造体
+ * レジストリに登録されるボスの性能
+ * @property {string} Name - ボス名（レジストリのキー）
+ * @property {int} Width - 幅（ピクセル）
+ * @property {int} Height - 高さ（ピクセル）
+ * @property {int} Health - 基本体力（難易度と参加人数で補正される）
+ * @property {float64} Speed - 左右移動の速度
+ * @property {float64} Y - 出現時のY座標
+ * @property {int} FireRate - 1ティックあたりの発射確率（1/60単位）
+ * @property {[]string} Patterns - 使用する攻撃パターン名のリスト
+ * @property {*Shape} Shape - 衝突形状（見た目に合わせて矩形の角を除く）
+ */
+type BossDefinition struct {
+	Name     string
+	Width    int
+	Height   int
+	Health   int
+	Speed    float64
+	Y        float64
+	FireRate int
+	Patterns []string
+	Shape    *Shape
+}
+
+/**
+ * レベル構造体
+ * 一つのステージの構成
+ * @property {int} Number - レベル番号（1始まり）
+ * @property {int} EnemiesToBoss - ボス出現に必要な撃破数
+ * @property {[]string} Bosses - 出現するボス名のリスト（同名の重複で複数体出現）
+ */
+type Level struct {
+	Number        int
+	EnemiesToBoss int
+	Bosses        []string
+}
+
+// ボスのレジストリ（キー：ボス名）
+var bossRegistry = map[string]BossDefinition{
+	"dragon": {
+		Name:     "dragon",
+		Width:    100,
+		Height:   80,
+		Health:   100,
+		Speed:    2,
+		Y:        50,
+		FireRate: 5,
+		Patterns: []string{"random"},
+		// 胴体の円と左右に広がる翼のカプセル
+		Shape: compoundShape(
+			circleShape(50, 45, 30),
+			capsuleShape(Vec2{12, 28}, Vec2{88, 28}, 12),
+		),
+	},
+	"wyvern": {
+		Name:     "wyvern",
+		Width:    70,
+		Height:   56,
+		Health:   60,
+		Speed:    3,
+		Y:        40,
+		FireRate: 3,
+		Patterns: []string{"aimed", "spread"},
+		// 菱形の胴体
+		Shape: polygonShape(Vec2{35, 0}, Vec2{70, 28}, Vec2{35, 56}, Vec2{0, 28}),
+	},
+	"mothership": {
+		Name:     "mothership",
+		Width:    160,
+		Height:   70,
+		Health:   200,
+		Speed:    1,
+		Y:        30,
+		FireRate: 4,
+		Patterns: []string{"ring", "aimed", "random"},
+		// 横長の船体とドーム
+		Shape: compoundShape(
+			capsuleShape(Vec2{30, 45}, Vec2{130, 45}, 25),
+			polygonShape(Vec2{55, 20}, Vec2{105, 20}, Vec2{120, 35}, Vec2{40, 35}),
+		),
+	},
+}
+
+// レベル構成（順番にプレイする）
+var levels = []Level{
+	{Number: 1, EnemiesToBoss: 20, Bosses: []string{"dragon"}},
+	{Number: 2, EnemiesToBoss: 25, Bosses: []string{"wyvern", "wyvern"}},
+	{Number: 3, EnemiesToBoss: 30, Bosses: []string{"mothership"}},
+}
+
+// ボスの攻撃パターン（キー：パターン名）
+var bossPatterns = map[string]func(gameRoom *GameRoom, boss *Entity){
+	"random": fireRandom,
+	"spread": fireSpread,
+	"aimed":  fireAimed,
+	"ring":   fireRing,
+}
+
+/**
+ * 現在のレベル構成を取得する（エンドレスモードでは現在のウェーブの構成）
+ * ボス出現に必要な撃破数はルームのルールで上書きされる
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @returns {Level} - 現在のレベル
+ */
+func currentLevel(gameRoom *GameRoom) Level {
+	level := levels[0]
+	if wave := endlessWave(gameRoom); wave > 0 {
+		level = waveLevel(wave)
+	} else if gameRoom.Level >= 1 && gameRoom.Level <= len(levels) {
+		level = levels[gameRoom.Level-1]
+	}
+	// ルールでボス出現に必要な撃破数を指定していればそれを使う（ボスのいないウェーブはそのまま）
+	if gameRoom.Rules.BossThreshold > 0 && len(level.Bosses) > 0 {
+		level.EnemiesToBoss = gameRoom.Rules.BossThreshold
+	}
+	return level
+}
+
+/**
+ * ボスの作成
+ * 現在のレベルに登録された全ボスを画面上部に横並びで生成する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ */
+func createBoss(gameRoom *GameRoom) {
+	gameRoom.Mutex.Lock()
+	defer gameRoom.Mutex.Unlock()
+
+	spawnBosses(gameRoom)
+}
+
+/**
+ * 現在のレベルに登録された全ボスを追加する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func spawnBosses(gameRoom *GameRoom) {
+	level := currentLevel(gameRoom)
+	for i, name := range level.Bosses {
+		def, ok := bossRegistry[name]
+		if !ok {
+			continue
+		}
+		health := scaledBossHealth(gameRoom, def.Health)
+		// 画面幅をボス数で等分した位置に配置
+		centerX := 800 / float64(len(level.Bosses)+1) * float64(i+1)
+		boss, c := gameRoom.entities.Spawn()
+		boss.Type = "boss"
+		boss.Kind = def.Name
+		boss.Transform = transformAt(centerX-float64(def.Width)/2, def.Y, def.Width, def.Height)
+		boss.Velocity = c.Velocity(Velocity{VelocityX: def.Speed, VelocityY: 0})
+		boss.Collider = c.Collider(Collider{Shape: def.Shape, Damage: 20}) // 接触ダメージ
+		boss.Health = c.Health(Health{Current: health, Max: health})
+		boss.AI = c.AI(AI{Update: bossAI})
+		// 双子ボスは逆方向に動かす
+		if i%2 == 1 {
+			boss.VelocityX *= -1
+		}
+		setLayer(boss, layerBoss)
+		gameRoom.Bosses[boss.ID] = boss
+	}
+	gameRoom.BossSpawned = true
+}
+
+/**
+ * ボスの攻撃
+ * ボス定義の発射確率に従い、パターンを一つ選んで弾を発射する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} boss - 攻撃するボス
+ */
+func bossAttack(gameRoom *GameRoom, boss *Entity) {
+	def, ok := bossRegistry[boss.Kind]
+	if !ok || len(def.Patterns) == 0 {
+		return
+	}
+	if gameRoom.combatRng.Intn(60) >= def.FireRate {
+		return
+	}
+	pattern := bossPatterns[def.Patterns[gameRoom.combatRng.Intn(len(def.Patterns))]]
+	if pattern != nil {
+		pattern(gameRoom, boss)
+	}
+}
+
+/**
+ * ボス弾の生成
+ * ボスの下端中央から指定速度で弾を発射する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} boss - 発射元のボス
+ * @param {float64} vx - X方向の速度
+ * @param {float64} vy - Y方向の速度
+ */
+func spawnBossBullet(gameRoom *GameRoom, boss *Entity, vx, vy float64) {
+	b, c := gameRoom.entities.Spawn()
+	b.Type = "bossBullet"
+	b.Transform = transformAt(boss.X+float64(boss.Width)/2, boss.Y+float64(boss.Height), 10, 10)
+	b.Velocity = c.Velocity(Velocity{VelocityX: vx, VelocityY: vy})
+	b.Collider = c.Collider(Collider{Shape: bossBulletShape, Damage: 15})
+	b.Lifetime = c.Lifetime(Lifetime{})
+	setLayer(b, layerEnemyShot)
+	initProjectile(b)
+	gameRoom.Bullets[b.ID] = b
+}
+
+/**
+ * ランダム弾: ランダムな水平速度で1発
+ */
+func fireRandom(gameRoom *GameRoom, boss *Entity) {
+	spawnBossBullet(gameRoom, boss, float64(gameRoom.combatRng.Intn(5)-2), float64(gameRoom.combatRng.Intn(3)+2))
+}
+
+/**
+ * 扇状弾: 下方向に5発を扇形に
+ */
+func fireSpread(gameRoom *GameRoom, boss *Entity) {
+	for i := -2; i <= 2; i++ {
+		spawnBossBullet(gameRoom, boss, float64(i)*1.2, 3)
+	}
+}
+
+/**
+ * 狙い撃ち: 最も近い生存プレイヤーへ1発
+ */
+func fireAimed(gameRoom *GameRoom, boss *Entity) {
+	originX := boss.X + float64(boss.Width)/2
+	originY := boss.Y + float64(boss.Height)
+	var target *Player
+	best := math.MaxFloat64
+	for _, p := range gameRoom.Players {
+		if p.Health.Current <= 0 {
+			continue
+		}
+		d := math.Hypot(p.X-originX, p.Y-originY)
+		if d < best {
+			best = d
+			target = p
+		}
+	}
+	if target == nil {
+		fireRandom(gameRoom, boss)
+		return
+	}
+	dx := target.X + float64(target.Width)/2 - originX
+	dy := target.Y + float64(target.Height)/2 - originY
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	const speed = 4.0
+	spawnBossBullet(gameRoom, boss, dx/length*speed, dy/length*speed)
+}
+
+/**
+ * 全方位弾: 12発を円形に
+ */
+func fireRing(gameRoom *GameRoom, boss *Entity) {
+	const count = 12
+	for i := 0; i < count; i++ {
+		angle := 2 * math.Pi * float64(i) / count
+		spawnBossBullet(gameRoom, boss, math.Cos(angle)*3, math.Sin(angle)*3)
+	}
+}
+
+/**
+ * レベルクリア処理
+ * レベルの全ボスを倒したときに呼ばれる。次のレベルがあれば進み、なければゲームクリア
+ * （エンドレスモードは常に次のウェーブへ、デイリーチャレンジはレベル1でクリア）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func levelCleared(gameRoom *GameRoom) {
+	// 全プレイヤーにクリア・ノーダメージ・タイムボーナス
+	awardStageBonuses(gameRoom)
+	finishStage(gameRoom, true)
+
+	mode := roomMode(gameRoom)
+	if gameRoom.Level >= len(levels) && !mode.Endless || mode.Daily {
+		gameRoom.GameState = "clear"
+		submitScores(gameRoom)
+		recordProfileStats(gameRoom)
+		return
+	}
+
+	// 次のレベルへ
+	gameRoom.Level++
+	gameRoom.EnemiesDefeated = 0
+	gameRoom.BossSpawned = false
+	despawnAll(gameRoom, gameRoom.Enemies)
+	despawnAll(gameRoom, gameRoom.Bullets)
+}
@@ -0,0 +1,93 @@
+/**
+ * @file boss_test.go
+ * @description ボスのレジストリとレベル構成のテスト
+ *
+ * 概要:
+ * - 全レベルのボスがレジストリに登録され、攻撃パターンが実装されていることを確認する
+ * - 双子ボスが横並びで逆方向に動くように出現することを確認する
+ * - レベルの全ボスを倒したときだけ次のレベルへ進むことを確認する
+ */
+
+package main
+
+import "testing"
+
+func TestLevelBossesRegistered(t *testing.T) {
+	for _, level := range levels {
+		if len(level.Bosses) == 0 {
+			t.Errorf("レベル%d にボスがいない", level.Number)
+		}
+		for _, name := range level.Bosses {
+			def, ok := bossRegistry[name]
+			if !ok {
+				t.Errorf("レベル%d のボス %q が未登録", level.Number, name)
+				continue
+			}
+			if def.Name != name || def.Shape == nil || len(def.Patterns) == 0 {
+				t.Errorf("ボス %q の定義が不完全: %+v", name, def)
+			}
+			for _, pattern := range def.Patterns {
+				if bossPatterns[pattern] == nil {
+					t.Errorf("ボス %q の攻撃パターン %q が未実装", name, pattern)
+				}
+			}
+		}
+	}
+}
+
+func TestTwinBossesSpawn(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	gameRoom.Level = 2
+	spawnBosses(gameRoom)
+
+	if !gameRoom.BossSpawned || len(gameRoom.Bosses) != 2 {
+		t.Fatalf("双子ボスが %d 体出現（出現済み %v）", len(gameRoom.Bosses), gameRoom.BossSpawned)
+	}
+	var left, right *Entity
+	for _, boss := range gameRoom.Bosses {
+		if boss.Kind != "wyvern" {
+			t.Errorf("ボスの種類 %q, want wyvern", boss.Kind)
+		}
+		if left == nil || boss.X < left.X {
+			left, right = boss, left
+		} else {
+			right = boss
+		}
+	}
+	if right.X-left.X < float64(left.Width) {
+		t.Errorf("双子ボスが重なって出現: x=%v, %v", left.X, right.X)
+	}
+	if left.VelocityX*right.VelocityX >= 0 {
+		t.Errorf("双子ボスが同じ向きに動く: %v, %v", left.VelocityX, right.VelocityX)
+	}
+}
+
+func TestLevelClearsAfterEveryBoss(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	testPlayer(gameRoom, "p1", 400, 500)
+	gameRoom.Level = 2
+	spawnBosses(gameRoom)
+	shot := &Entity{Collider: &Collider{Owner: "p1"}}
+
+	var bosses []*Entity
+	for _, boss := range gameRoom.Bosses {
+		bosses = append(bosses, boss)
+	}
+	defeatBoss(gameRoom, bosses[0], shot)
+	if gameRoom.Level != 2 || !gameRoom.BossSpawned {
+		t.Fatalf("1体目の撃破でレベルが進んだ: レベル%d", gameRoom.Level)
+	}
+	defeatBoss(gameRoom, bosses[1], shot)
+	if gameRoom.Level != 3 || gameRoom.BossSpawned || gameRoom.EnemiesDefeated != 0 {
+		t.Fatalf("全ボスの撃破で次のレベルへ進まない: レベル%d（出現済み %v）", gameRoom.Level, gameRoom.BossSpawned)
+	}
+
+	// 最終レベルのボスを倒すとクリア
+	spawnBosses(gameRoom)
+	for _, boss := range gameRoom.Bosses {
+		defeatBoss(gameRoom, boss, shot)
+	}
+	if gameRoom.GameState != "clear" {
+		t.Fatalf("最終レベルのボスを倒してもクリアにならない: %s", gameRoom.GameState)
+	}
+}
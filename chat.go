@@ -0,0 +1,253 @@
+/**
+ * @file chat.go
+ * @description ルーム内のテキストチャットとモデレーション
+ *
+ * 概要:
+ * - "chat" メッセージで送られた発言を、長さ制限・禁止語の伏せ字処理をしてルームに配信する
+ * - プレイヤーごとに一定時間内の発言数を制限する
+ * - ルームのホスト（最初に参加したプレイヤー、抜けたら次のプレイヤー）は発言の禁止（ミュート）とキックができる
+ * - キックされたプレイヤー（ログイン中ならアカウント、ゲストならサーバーが Cookie で発行したゲストID）は同じルームに戻れない
+ * - 直近の発言は履歴として保持し、途中から参加したプレイヤーに送る
+ */
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// 発言の最大文字数
+	maxChatLength = 200
+	// 保持する発言履歴の件数
+	chatHistorySize = 50
+	// 発言数を数える期間と、その期間に許す発言数
+	chatRateWindow = 10 * time.Second
+	chatRateLimit  = 5
+)
+
+var (
+	errChatEmpty     = errors.New("発言を入力してください")
+	errChatTooLong   = errors.New("発言は200文字以内にしてください")
+	errChatRateLimit = errors.New("発言が多すぎます。少し待ってから送信してください")
+	errChatMuted     = errors.New("ホストによって発言が禁止されています")
+	errNotHost       = errors.New("この操作はホストのみ行えます")
+	errNoSuchPlayer  = errors.New("プレイヤーが見つかりません")
+)
+
+/**
+ * チャットの発言構造体
+ * @property {string} PlayerID - 発言したプレイヤーのID（システムの通知は空）
+ * @property {string} Name - 発言したプレイヤーの名前
+ * @property {string} Color - 発言したプレイヤーの色
+ * @property {string} Text - 発言（伏せ字処理済み）
+ * @property {bool} System - システムの通知か（参加・退出・キックなど）
+ * @property {int64} Time - 発言した時刻（UNIXミリ秒）
+ */
+type ChatMessage struct {
+	PlayerID string `json:"playerId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Text     string `json:"text"`
+	System   bool   `json:"system,omitempty"`
+	Time     int64  `json:"time"`
+}
+
+/**
+ * 発言を整形・検証する
+ * 改行などの制御文字は空白にし、禁止語は伏せ字にする
+ * @param {string} text - 入力された発言
+ * @returns {string, error} - 整形した発言と検証エラー
+ */
+func sanitizeChat(text string) (string, error) {
+	text = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text))
+	if text == "" {
+		return "", errChatEmpty
+	}
+	if utf8.RuneCountInString(text) > maxChatLength {
+		return "", errChatTooLong
+	}
+	return censorBannedWords(text), nil
+}
+
+/**
+ * プレイヤーの発言を配信する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {*Player} p - 発言したプレイヤー
+ * @param {string} text - 入力された発言
+ * @returns {error} - 発言できなかった理由（あれば）
+ */
+func postChat(gameRoom *GameRoom, p *Player, text string) error {
+	text, err := sanitizeChat(text)
+	if err != nil {
+		return err
+	}
+
+	gameRoom.Mutex.Lock()
+	if gameRoom.muted[p.identity] {
+		gameRoom.Mutex.Unlock()
+		return errChatMuted
+	}
+
+	// 直近の発言数で制限する
+	now := time.Now()
+	recent := p.chatTimes[:0]
+	for _, t := range p.chatTimes {
+		if now.Sub(t) < chatRateWindow {
+			recent = append(recent, t)
+		}
+	}
+	p.chatTimes = recent
+	if len(p.chatTimes) >= chatRateLimit {
+		gameRoom.Mutex.Unlock()
+		return errChatRateLimit
+	}
+	p.chatTimes = append(p.chatTimes, now)
+
+	msg := addChat(gameRoom, ChatMessage{PlayerID: p.ID, Name: p.Name, Color: p.Color, Text: text, Time: now.UnixMilli()})
+	gameRoom.Mutex.Unlock()
+
+	broadcastMessage(gameRoom, Message{Type: "chat", Data: msg})
+	return nil
+}
+
+/**
+ * システムの通知をチャットに流す
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {string} text - 通知の文
+ */
+func postSystemChat(gameRoom *GameRoom, text string) {
+	gameRoom.Mutex.Lock()
+	msg := addChat(gameRoom, ChatMessage{Text: text, System: true, Time: time.Now().UnixMilli()})
+	gameRoom.Mutex.Unlock()
+
+	broadcastMessage(gameRoom, Message{Type: "chat", Data: msg})
+}
+
+/**
+ * 発言を履歴に追加する（古い発言から捨てる）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {ChatMessage} msg - 発言
+ * @returns {ChatMessage} - 追加した発言
+ */
+func addChat(gameRoom *GameRoom, msg ChatMessage) ChatMessage {
+	gameRoom.chat = append(gameRoom.chat, msg)
+	if over := len(gameRoom.chat) - chatHistorySize; over > 0 {
+		gameRoom.chat = append(gameRoom.chat[:0], gameRoom.chat[over:]...)
+	}
+	return msg
+}
+
+/**
+ * 発言履歴のコピーを取得する（途中参加のプレイヤーに送る）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {[]ChatMessage} - 発言履歴（古い順）
+ */
+func chatHistory(gameRoom *GameRoom) []ChatMessage {
+	return append([]ChatMessage{}, gameRoom.chat...)
+}
+
+/**
+ * ホストがプレイヤーの発言を禁止・解除する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {*Player} host - 操作したプレイヤー
+ * @param {string} targetID - 対象のプレイヤーID
+ * @param {bool} muted - trueなら禁止、falseなら解除
+ * @returns {error} - 操作できなかった理由（あれば）
+ */
+func setMuted(gameRoom *GameRoom, host *Player, targetID string, muted bool) error {
+	gameRoom.Mutex.Lock()
+	if gameRoom.Host != host.ID {
+		gameRoom.Mutex.Unlock()
+		return errNotHost
+	}
+	target, ok := gameRoom.Players[targetID]
+	if !ok || target == host {
+		gameRoom.Mutex.Unlock()
+		return errNoSuchPlayer
+	}
+	if muted {
+		gameRoom.muted[target.identity] = true
+	} else {
+		delete(gameRoom.muted, target.identity)
+	}
+	name := target.Name
+	gameRoom.Mutex.Unlock()
+
+	if muted {
+		postSystemChat(gameRoom, name+" の発言が禁止されました")
+	} else {
+		postSystemChat(gameRoom, name+" の発言禁止が解除されました")
+	}
+	return nil
+}
+
+/**
+ * ホストがプレイヤーをルームから追い出す
+ * 対象の接続を閉じ、同じ接続元・アカウントからはこのルームに参加できないようにする
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {*Player} host - 操作したプレイヤー
+ * @param {string} targetID - 対象のプレイヤーID
+ * @returns {error} - 操作できなかった理由（あれば）
+ */
+func kickPlayer(gameRoom *GameRoom, host *Player, targetID string) error {
+	gameRoom.Mutex.Lock()
+	if gameRoom.Host != host.ID {
+		gameRoom.Mutex.Unlock()
+		return errNotHost
+	}
+	target, ok := gameRoom.Players[targetID]
+	if !ok || target == host {
+		gameRoom.Mutex.Unlock()
+		return errNoSuchPlayer
+	}
+	name := target.Name
+	gameRoom.banned[target.identity] = true
+	gameRoom.Mutex.Unlock()
+
+	clientsMutex.Lock()
+	client := clients[targetID]
+	clientsMutex.Unlock()
+	if client == nil {
+		return errNoSuchPlayer
+	}
+
+	// 切断はメッセージ処理ループの読み込みエラーとして処理される
+	sendMessage(client, Message{Type: "kicked", Data: map[string]string{"message": "ホストによってルームから追い出されました"}})
+	client.Socket.Close()
+
+	postSystemChat(gameRoom, name+" がルームから追い出されました")
+	return nil
+}
+
+/**
+ * プレイヤーの退出時にホストを引き継ぐ
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {string} - 新しいホストの名前（引き継ぎがなければ空）
+ */
+func reassignHost(gameRoom *GameRoom) string {
+	if _, ok := gameRoom.Players[gameRoom.Host]; ok {
+		return ""
+	}
+	gameRoom.Host = ""
+	var next *Player
+	for _, p := range gameRoom.Players {
+		if next == nil || p.joinedAt.Before(next.joinedAt) {
+			next = p
+		}
+	}
+	if next == nil {
+		return ""
+	}
+	gameRoom.Host = next.ID
+	return next.Name
+}
@@ -0,0 +1,189 @@
+/**
+ * @file chat_test.go
+ * @description ルーム内のチャットとモデレーションのテスト
+ *
+ * 概要:
+ * - 発言の整形・長さ制限・伏せ字処理と、発言数の制限を確認する
+ * - ミュートはホストだけが行え、同じゲストIDで参加し直しても解除されないことを確認する
+ * - キックされたプレイヤーに通知が届いて切断され、同じゲストIDでは戻れないことを確認する
+ * - 発言履歴が上限の件数に保たれることを確認する
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+/**
+ * ゲストIDを持つテスト用のプレイヤーを作成してルームに追加する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {string} id - プレイヤーID
+ * @param {string} guest - ゲストID
+ * @returns {*Player} - プレイヤー
+ */
+func chatPlayer(gameRoom *GameRoom, id, guest string) *Player {
+	p := testPlayer(gameRoom, id, 100, 500)
+	p.identity = "guest:" + guest
+	p.joinedAt = time.Now()
+	if gameRoom.Host == "" {
+		gameRoom.Host = p.ID
+	}
+	return p
+}
+
+func TestSanitizeChat(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"  hello\nworld  ", "hello world", nil},
+		{"gg you shit", "gg you ****", nil},
+		{"\n\t", "", errChatEmpty},
+		{strings.Repeat("あ", maxChatLength), strings.Repeat("あ", maxChatLength), nil},
+		{strings.Repeat("あ", maxChatLength+1), "", errChatTooLong},
+	}
+	for _, tt := range tests {
+		got, err := sanitizeChat(tt.input)
+		if got != tt.want || err != tt.err {
+			t.Errorf("sanitizeChat(%q) = %q, %v, want %q, %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestChatRateLimit(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := chatPlayer(gameRoom, "p1", "g1")
+
+	for i := 0; i < chatRateLimit; i++ {
+		if err := postChat(gameRoom, p, "hi"); err != nil {
+			t.Fatalf("%d件目: %v", i+1, err)
+		}
+	}
+	if err := postChat(gameRoom, p, "hi"); err != errChatRateLimit {
+		t.Fatalf("上限を超えた発言: %v, want %v", err, errChatRateLimit)
+	}
+
+	// 期間を過ぎた発言は数えない
+	for i := range p.chatTimes {
+		p.chatTimes[i] = p.chatTimes[i].Add(-chatRateWindow)
+	}
+	if err := postChat(gameRoom, p, "hi again"); err != nil {
+		t.Fatalf("期間を過ぎても発言できない: %v", err)
+	}
+	if history := chatHistory(gameRoom); len(history) != chatRateLimit+1 {
+		t.Errorf("履歴 %d 件, want %d", len(history), chatRateLimit+1)
+	}
+}
+
+func TestChatHistoryKeepsLatest(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	for i := 0; i < chatHistorySize+10; i++ {
+		addChat(gameRoom, ChatMessage{Text: strings.Repeat("x", i+1)})
+	}
+	history := chatHistory(gameRoom)
+	if len(history) != chatHistorySize || len(history[0].Text) != 11 {
+		t.Fatalf("履歴 %d 件、最古 %d 文字, want %d 件・11 文字", len(history), len(history[0].Text), chatHistorySize)
+	}
+	history[0].Text = "changed"
+	if gameRoom.chat[0].Text == "changed" {
+		t.Error("取得した履歴がルームの履歴と共有されている")
+	}
+}
+
+func TestMuteByHost(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	host := chatPlayer(gameRoom, "host", "g-host")
+	target := chatPlayer(gameRoom, "target", "g-target")
+
+	if err := setMuted(gameRoom, target, host.ID, true); err != errNotHost {
+		t.Fatalf("ホスト以外のミュート: %v, want %v", err, errNotHost)
+	}
+	if err := setMuted(gameRoom, host, host.ID, true); err != errNoSuchPlayer {
+		t.Fatalf("自分自身のミュート: %v, want %v", err, errNoSuchPlayer)
+	}
+	if err := setMuted(gameRoom, host, target.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := postChat(gameRoom, target, "hello"); err != errChatMuted {
+		t.Fatalf("ミュート中の発言: %v, want %v", err, errChatMuted)
+	}
+
+	// 同じゲストIDで参加し直してもミュートは続く
+	delete(gameRoom.Players, target.ID)
+	rejoined := chatPlayer(gameRoom, "target2", "g-target")
+	if err := postChat(gameRoom, rejoined, "hello"); err != errChatMuted {
+		t.Fatalf("参加し直した後の発言: %v, want %v", err, errChatMuted)
+	}
+
+	if err := setMuted(gameRoom, host, rejoined.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := postChat(gameRoom, rejoined, "hello"); err != nil {
+		t.Fatalf("解除後も発言できない: %v", err)
+	}
+}
+
+func TestKickByHost(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	host := chatPlayer(gameRoom, "host", "g-host")
+	target := chatPlayer(gameRoom, "kick-target", "g-kicked")
+
+	// 対象の接続（サーバー側を clients に登録し、クライアント側で通知を受け取る）
+	accepted := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		accepted <- conn
+	}))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	clientsMutex.Lock()
+	clients[target.ID] = &Client{ID: target.ID, Socket: <-accepted, GameRoom: gameRoom, Player: target}
+	clientsMutex.Unlock()
+	defer func() {
+		clientsMutex.Lock()
+		delete(clients, target.ID)
+		clientsMutex.Unlock()
+	}()
+
+	if err := kickPlayer(gameRoom, target, host.ID); err != errNotHost {
+		t.Fatalf("ホスト以外のキック: %v, want %v", err, errNotHost)
+	}
+	if err := kickPlayer(gameRoom, host, target.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var kicked Message
+	if _, data, err := conn.ReadMessage(); err != nil || json.Unmarshal(data, &kicked) != nil || kicked.Type != "kicked" {
+		t.Fatalf("キックの通知が届かない: %v %+v", err, kicked)
+	}
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("キック後も接続が閉じられない")
+	}
+
+	// 同じゲストIDでは戻れないが、他のゲストは参加できる
+	delete(gameRoom.Players, target.ID)
+	if canJoin(gameRoom, &Player{identity: "guest:g-kicked"}) {
+		t.Error("キックされたゲストが参加できる")
+	}
+	if !canJoin(gameRoom, &Player{identity: "guest:g-other"}) {
+		t.Error("他のゲストが参加できない")
+	}
+}
@@ -0,0 +1,472 @@
+/**
+ * @file collision.go
+ * @description 衝突レイヤー・マスクと衝突応答テーブル
+ *
+ * 概要:
+ * - 各エンティティは所属レイヤーと、衝突対象のレイヤーを表すマスクを持つ
+ * - (発生元レイヤー, 対象レイヤー) の組ごとに衝突応答を登録する
+ * - 衝突処理は全エンティティを対象に汎用的に行い、種類ごとの分岐を持たない
+ *
+ * 新しい種類のエンティティを追加する場合は、レイヤーとマスクを設定し、
+ * 必要な衝突応答をテーブルに登録する
+ */
+
+package main
+
+import "sort"
+
+// 衝突レイヤー（ビットフラグ）
+type CollisionLayer uint8
+
+const (
+	layerPlayer     CollisionLayer = 1 << iota // プレイヤー機体
+	layerPlayerShot                            // プレイヤーの弾
+	layerEnemy                                 // 雑魚敵
+	layerEnemyShot                             // 敵・ボスの弾
+	layerPickup                                // アイテム
+	layerBoss                                  // ボス
+)
+
+// レイヤーごとの既定のマスク（衝突対象のレイヤー）
+var layerMasks = map[CollisionLayer]CollisionLayer{
+	layerPlayer:     layerEnemy | layerEnemyShot | layerPickup | layerBoss,
+	layerPlayerShot: layerEnemy | layerBoss,
+	layerEnemy:      layerPlayer | layerPlayerShot,
+	layerEnemyShot:  layerPlayer,
+	layerPickup:     layerPlayer,
+	layerBoss:       layerPlayer | layerPlayerShot,
+}
+
+/**
+ * 衝突応答構造体
+ * @property {bool} AABB - trueなら衝突形状を無視して矩形で判定する
+ * @property {func(*GameRoom, *Entity, *Entity) bool} Handle - 応答処理（falseを返すと発生元の判定を打ち切る）
+ */
+type CollisionResponse struct {
+	AABB   bool
+	Handle func(gameRoom *GameRoom, source, target *Entity) bool
+}
+
+// 衝突応答テーブル（キー：発生元レイヤーと対象レイヤーの組）
+var collisionResponses = map[[2]CollisionLayer]CollisionResponse{
+	{layerPlayerShot, layerEnemy}: {Handle: shotHitsTarget},
+	{layerPlayerShot, layerBoss}:  {Handle: shotHitsTarget},
+	{layerEnemyShot, layerPlayer}: {Handle: shotHitsPlayer},
+	{layerEnemy, layerPlayer}:     {Handle: enemyRamsPlayer},
+	{layerBoss, layerPlayer}:      {Handle: bossRamsPlayer},
+	{layerPickup, layerPlayer}:    {AABB: true, Handle: pickupCollected},
+	// PvPのモード・フレンドリーファイアのルールのみ（弾のマスクに layerPlayer を加えたときだけ判定される）
+	{layerPlayerShot, layerPlayer}: {Handle: shotHitsRival},
+	// チーム対戦のみ（弾のマスクに layerPickup を加えたときだけ判定される）
+	{layerPlayerShot, layerPickup}: {Handle: shotHitsItem},
+}
+
+/**
+ * エンティティにレイヤーと既定のマスクを設定する
+ * @param {*Entity} e - エンティティ
+ * @param {CollisionLayer} layer - 所属レイヤー
+ */
+func setLayer(e *Entity, layer CollisionLayer) {
+	e.Layer = layer
+	e.Mask = layerMasks[layer]
+}
+
+/**
+ * いずれかの衝突応答で対象になっているレイヤーの集合
+ * @returns {CollisionLayer} - 対象レイヤーのビット和
+ */
+func targetedLayers() CollisionLayer {
+	var layers CollisionLayer
+	for pair := range collisionResponses {
+		layers |= pair[1]
+	}
+	return layers
+}
+
+/**
+ * ルーム内の全エンティティを列挙する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {func(*Entity)} visit - エンティティごとのコールバック
+ */
+func forEachEntity(gameRoom *GameRoom, visit func(e *Entity)) {
+	for _, p := range gameRoom.Players {
+		visit(&p.Entity)
+	}
+	for _, e := range gameRoom.Enemies {
+		visit(e)
+	}
+	for _, boss := range gameRoom.Bosses {
+		visit(boss)
+	}
+	for _, b := range gameRoom.Bullets {
+		visit(b)
+	}
+	for _, it := range gameRoom.Items {
+		visit(it)
+	}
+}
+
+/**
+ * エンティティをルームから取り除き、プールに返却する
+ * 衝突処理中に同じティックで再度判定されないよう削除済みの印が付く
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} e - 取り除くエンティティ
+ */
+func despawn(gameRoom *GameRoom, e *Entity) {
+	if e.removed {
+		return
+	}
+	gameRoom.entities.Release(e)
+	switch e.Layer {
+	case layerEnemy:
+		delete(gameRoom.Enemies, e.ID)
+	case layerBoss:
+		delete(gameRoom.Bosses, e.ID)
+	case layerPlayerShot, layerEnemyShot:
+		delete(gameRoom.Bullets, e.ID)
+	case layerPickup:
+		delete(gameRoom.Items, e.ID)
+	}
+}
+
+/**
+ * マップ内の全エンティティを取り除く（レベル移行やリスタート時）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {map[EntityID]*Entity} entities - 対象のマップ
+ */
+func despawnAll(gameRoom *GameRoom, entities map[EntityID]*Entity) {
+	for _, e := range entities {
+		despawn(gameRoom, e)
+	}
+}
+
+/**
+ * 衝突相手のエンティティに対応するプレイヤーを探す
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} e - エンティティ
+ * @returns {*Player} - プレイヤー（プレイヤーでなければnil）
+ */
+func playerByEntity(gameRoom *GameRoom, e *Entity) *Player {
+	for _, p := range gameRoom.Players {
+		if &p.Entity == e {
+			return p
+		}
+	}
+	return nil
+}
+
+/**
+ * ブロードフェーズ用グリッドの再構築
+ * 衝突応答の対象になっているレイヤーごとにグリッドを作り、エンティティを登録する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func rebuildGrids(gameRoom *GameRoom) {
+	if gameRoom.grids == nil {
+		gameRoom.grids = make(map[CollisionLayer]*SpatialGrid)
+		targets := targetedLayers()
+		for layer := layerPlayer; layer <= layerBoss; layer <<= 1 {
+			if targets&layer != 0 {
+				gameRoom.grids[layer] = newSpatialGrid(worldWidth, worldHeight, gridCellSize)
+			}
+		}
+	}
+	for _, grid := range gameRoom.grids {
+		grid.Clear()
+	}
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.Collider == nil {
+			return
+		}
+		if grid, ok := gameRoom.grids[e.Layer]; ok {
+			grid.Insert(e)
+		}
+	})
+}
+
+/**
+ * 衝突候補構造体
+ * @property {*Entity} target - 衝突相手
+ * @property {CollisionResponse} response - 衝突応答
+ * @property {float64} toi - 発生元が相手に届く時刻（高速な弾のみ、それ以外は0）
+ */
+type contact struct {
+	target   *Entity
+	response CollisionResponse
+	toi      float64
+}
+
+/**
+ * エンティティを生成順に並べる
+ * 応答やAIの中で乱数を引くため、マップの列挙順によらない順序で処理する（プレイヤー機体は先頭）
+ * @param {[]*Entity} entities - エンティティ
+ */
+func sortBySpawn(entities []*Entity) {
+	sort.SliceStable(entities, func(i, j int) bool {
+		return entities[i].seq < entities[j].seq
+	})
+}
+
+/**
+ * 衝突処理
+ * マスクで対象となるレイヤーのグリッドから候補を取り出し、応答テーブルの処理を呼ぶ
+ * 発生元・レイヤー・衝突相手はいずれも決まった順に処理する（デイリーチャレンジのドロップを再現するため）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {bool} - レベルクリアやゲームオーバーで以降の更新を中断すべき場合true
+ */
+func resolveCollisions(gameRoom *GameRoom) bool {
+	rebuildGrids(gameRoom)
+
+	// 処理中にマップが変化するため、発生元を先に集める
+	var sources []*Entity
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.Collider != nil && e.Mask != 0 {
+			sources = append(sources, e)
+		}
+	})
+	sortBySpawn(sources)
+
+	level := gameRoom.Level
+	var contacts []contact
+	for _, source := range sources {
+		if source.removed {
+			continue
+		}
+		contacts = collectContacts(gameRoom, source, contacts[:0])
+		for _, c := range contacts {
+			if c.target.removed {
+				continue
+			}
+			if !c.response.Handle(gameRoom, source, c.target) || source.removed {
+				break
+			}
+			// レベルクリア・ゲームオーバーで状態が変わったら中断
+			if gameRoom.GameState != "playing" || gameRoom.Level != level {
+				return true
+			}
+		}
+		if gameRoom.GameState != "playing" || gameRoom.Level != level {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 発生元と衝突している相手を集める
+ * 高速な弾は経路上で先に届く相手から、同時ならレイヤーのビット順・生成順に並べる
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} source - 発生元
+ * @param {[]contact} contacts - 追加先（容量を再利用する）
+ * @returns {[]contact} - 衝突している相手
+ */
+func collectContacts(gameRoom *GameRoom, source *Entity, contacts []contact) []contact {
+	// 高速な弾は移動経路全体を検索範囲にする
+	bounds := source
+	if source.Fast {
+		swept := sweptBounds(source, source.prevX, source.prevY)
+		bounds = &swept
+	}
+	for layer := layerPlayer; layer <= layerBoss; layer <<= 1 {
+		grid, ok := gameRoom.grids[layer]
+		if !ok || source.Mask&layer == 0 {
+			continue
+		}
+		response, ok := collisionResponses[[2]CollisionLayer{source.Layer, layer}]
+		if !ok {
+			continue
+		}
+		start := len(contacts)
+		grid.Query(bounds, func(target *Entity) bool {
+			if target.removed || target == source {
+				return true
+			}
+			if response.AABB {
+				if !checkAABB(source, target) {
+					return true
+				}
+			} else if !bulletHits(source, source.prevX, source.prevY, target) {
+				return true
+			}
+			c := contact{target: target, response: response}
+			if source.Fast {
+				c.toi = sweepEntryTime(source, source.prevX, source.prevY, target)
+			}
+			contacts = append(contacts, c)
+			return true
+		})
+		if found := contacts[start:]; len(found) > 1 {
+			sort.SliceStable(found, func(i, j int) bool {
+				return found[i].target.seq < found[j].target.seq
+			})
+		}
+	}
+	if source.Fast && len(contacts) > 1 {
+		sort.SliceStable(contacts, func(i, j int) bool {
+			return contacts[i].toi < contacts[j].toi
+		})
+	}
+	return contacts
+}
+
+/**
+ * 弾の命中登録
+ * 同じ標的への多重ヒットを防ぎ、貫通回数を消費する。貫通回数が尽きた弾は削除する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} b - 弾
+ * @param {*Entity} target - 命中した標的
+ * @returns {bool} - 新しい命中として扱う場合true（既に命中済みならfalse）
+ */
+func registerHit(gameRoom *GameRoom, b *Entity, target *Entity) bool {
+	for _, hitID := range b.hits {
+		if hitID == target.ID {
+			return false
+		}
+	}
+	b.hits = append(b.hits, target.ID)
+	consumePierce(gameRoom, b)
+	return true
+}
+
+/**
+ * プレイヤーへの弾の命中登録
+ * プレイヤーのエンティティはプールのハンドルを持たないため、プレイヤーIDで多重ヒットを防ぐ
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} b - 弾
+ * @param {*Player} p - 命中したプレイヤー
+ * @returns {bool} - 新しい命中として扱う場合true（既に命中済みならfalse）
+ */
+func registerPlayerHit(gameRoom *GameRoom, b *Entity, p *Player) bool {
+	for _, id := range b.hitPlayers {
+		if id == p.ID {
+			return false
+		}
+	}
+	b.hitPlayers = append(b.hitPlayers, p.ID)
+	consumePierce(gameRoom, b)
+	return true
+}
+
+/**
+ * 貫通回数を消費する。尽きた弾は削除する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} b - 弾
+ */
+func consumePierce(gameRoom *GameRoom, b *Entity) {
+	if b.Pierce > 0 {
+		b.Pierce--
+	} else {
+		despawn(gameRoom, b)
+	}
+}
+
+/**
+ * 衝突応答: プレイヤーの弾が敵・ボスに命中
+ * 体力が0になったときだけ撃破する
+ */
+func shotHitsTarget(gameRoom *GameRoom, b, target *Entity) bool {
+	if target.Health == nil || target.Health.Current <= 0 || !registerHit(gameRoom, b, target) {
+		return true
+	}
+	target.Health.Current -= b.Damage
+	recordAttacker(target, b.Owner)
+	if target.Layer == layerBoss {
+		awardOwner(gameRoom, b.Owner, b.Damage*scorePoints[scoreBossDamage], scoreBossDamage)
+	}
+	if target.Health.Current <= 0 {
+		switch target.Layer {
+		case layerBoss:
+			defeatBoss(gameRoom, target, b)
+		case layerEnemy:
+			defeatEnemy(gameRoom, target, b)
+		}
+	}
+	return true
+}
+
+/**
+ * 衝突応答: 敵・ボスの弾がプレイヤーに命中
+ */
+func shotHitsPlayer(gameRoom *GameRoom, b, target *Entity) bool {
+	p := playerByEntity(gameRoom, target)
+	if p == nil {
+		return true
+	}
+	despawn(gameRoom, b)
+	damagePlayer(gameRoom, p, b)
+	return false
+}
+
+/**
+ * 衝突応答: 雑魚敵がプレイヤーに体当たり（ダメージを与えたら敵は消滅）
+ * 無敵時間中・シールド中で無効化された場合は、押し返すだけで敵は残る
+ */
+func enemyRamsPlayer(gameRoom *GameRoom, enemy, target *Entity) bool {
+	p := playerByEntity(gameRoom, target)
+	if p == nil || !damagePlayer(gameRoom, p, enemy) {
+		return true
+	}
+	despawn(gameRoom, enemy)
+	return false
+}
+
+/**
+ * 衝突応答: ボスがプレイヤーに接触
+ * 接触し続けてもクールダウン中はダメージを与えない
+ */
+func bossRamsPlayer(gameRoom *GameRoom, boss, target *Entity) bool {
+	if p := playerByEntity(gameRoom, target); p != nil {
+		damagePlayer(gameRoom, p, boss)
+	}
+	return true
+}
+
+/**
+ * 衝突応答: プレイヤーがアイテムを取得
+ */
+func pickupCollected(gameRoom *GameRoom, item, target *Entity) bool {
+	p := playerByEntity(gameRoom, target)
+	if p == nil {
+		return true
+	}
+	despawn(gameRoom, item)
+	for _, recipient := range itemRecipients(gameRoom, p, item.Item) {
+		applyItem(gameRoom, recipient, item.Item)
+	}
+	return false
+}
+
+/**
+ * 雑魚敵の撃破処理
+ * 敵を削除し、撃破数・ドロップ・スコアを処理する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} e - 倒された敵
+ * @param {*Entity} b - とどめを刺した弾
+ */
+func defeatEnemy(gameRoom *GameRoom, e *Entity, b *Entity) {
+	despawn(gameRoom, e)
+	gameRoom.EnemiesDefeated++
+
+	// 敵倒時にアーキタイプのドロップテーブルでアイテムを落とす
+	dropItem(gameRoom, enemyArchetype(e.Kind).DropTable, e.X, e.Y)
+
+	// とどめを刺した弾の持ち主に撃破点、他の攻撃者にアシスト点
+	awardKill(gameRoom, e, b.Owner, scoreKill)
+}
+
+/**
+ * ボスの撃破処理
+ * ボスを削除してアイテムを落とし、レベルの全ボスを倒していればレベルクリアにする
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} boss - 倒されたボス
+ * @param {*Entity} b - とどめを刺した弾
+ */
+func defeatBoss(gameRoom *GameRoom, boss *Entity, b *Entity) {
+	despawn(gameRoom, boss)
+	awardKill(gameRoom, boss, b.Owner, scoreBossKill)
+	dropItem(gameRoom, "boss", boss.X+float64(boss.Width)/2, boss.Y+float64(boss.Height)/2)
+
+	// レベルの全ボスを倒したらレベルクリア
+	if gameRoom.BossSpawned && len(gameRoom.Bosses) == 0 {
+		levelCleared(gameRoom)
+	}
+}
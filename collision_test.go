@@ -0,0 +1,206 @@
+/**
+ * @file collision_test.go
+ * @description 衝突判定と衝突応答のテスト
+ *
+ * 概要:
+ * - 弾のダメージで敵の体力が減り、0になったときだけ撃破されることを確認する
+ * - 貫通する弾が貫通回数+1体まで命中し、同じ敵には一度しか当たらないことを確認する
+ * - 衝突応答テーブルの組が既定のマスク（または任意で有効にする組）と一致することを確認する
+ * - モードとルールに応じて自弾のマスクが変わることを確認する
+ * - 応答のないレイヤーの組は重なっても何も起きないことを確認する
+ * - アイテムは喰らい判定ではなく機体の矩形で取得されることを確認する
+ */
+
+package main
+
+import "testing"
+
+/**
+ * 止まっている敵を追加する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {string} kind - アーキタイプ名
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @returns {*Entity} - 敵
+ */
+func stillEnemy(gameRoom *GameRoom, kind string, x, y float64) *Entity {
+	e := spawnEnemy(gameRoom, enemyArchetype(kind), x)
+	e.Y = y
+	e.VelocityX, e.VelocityY = 0, 0
+	return e
+}
+
+/**
+ * 弾を指定位置に置く（前ティックの位置も同じにして掃引しない）
+ * @param {*Entity} b - 弾
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ */
+func placeBullet(b *Entity, x, y float64) {
+	b.X, b.Y = x, y
+	b.prevX, b.prevY = x, y
+}
+
+func TestBulletDamagesEnemyHealth(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	tank := stillEnemy(gameRoom, "tank", 100, 100)
+
+	for shot := 1; shot <= 3; shot++ {
+		b, _ := spawnPlayerBullet(gameRoom, p, weaponRail, 0, 0, 0, 4, 20, 3, 0)
+		placeBullet(b, 116, 110)
+		resolveCollisions(gameRoom)
+		gameRoom.entities.Flush()
+
+		if gameRoom.entities.Get(b.ID) != nil {
+			t.Fatalf("%d発目: 貫通しない弾が命中後も残っている", shot)
+		}
+		want := enemyArchetype("tank").Health - 3*shot
+		if _, alive := gameRoom.Enemies[tank.ID]; alive != (want > 0) {
+			t.Fatalf("%d発目: 残っている = %v（体力 %d）", shot, alive, want)
+		}
+		if want > 0 && tank.Health.Current != want {
+			t.Fatalf("%d発目: 体力 %d, want %d", shot, tank.Health.Current, want)
+		}
+	}
+	if gameRoom.EnemiesDefeated != 1 {
+		t.Errorf("撃破数 %d, want 1", gameRoom.EnemiesDefeated)
+	}
+}
+
+func TestPiercingBulletHitsLimitedEnemies(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	var grunts []*Entity
+	for i := 0; i < 3; i++ {
+		e := stillEnemy(gameRoom, "grunt", 100, 100+float64(i)*40)
+		e.Health.Current, e.Health.Max = 5, 5
+		grunts = append(grunts, e)
+	}
+
+	// 3体を縦に貫く長い弾（貫通回数1なら2体目で消える）
+	b, _ := spawnPlayerBullet(gameRoom, p, weaponLaser, 0, 0, 0, 4, 120, 1, 1)
+	placeBullet(b, 113, 95)
+	resolveCollisions(gameRoom)
+	gameRoom.entities.Flush()
+
+	hit := 0
+	for _, e := range grunts {
+		if e.Health.Current < 5 {
+			hit++
+		}
+	}
+	if hit != 2 {
+		t.Errorf("貫通回数1の弾が %d 体に命中, want 2", hit)
+	}
+	if gameRoom.entities.Get(b.ID) != nil {
+		t.Error("貫通回数が尽きた弾が残っている")
+	}
+
+	// 重なり続けても同じ敵には一度しか当たらない
+	b, _ = spawnPlayerBullet(gameRoom, p, weaponLaser, 0, 0, 0, 4, 20, 1, 10)
+	placeBullet(b, 113, 185)
+	before := grunts[2].Health.Current
+	for i := 0; i < 5; i++ {
+		resolveCollisions(gameRoom)
+	}
+	if got := before - grunts[2].Health.Current; got != 1 {
+		t.Errorf("5ティック重なって %d ダメージ, want 1", got)
+	}
+	if b.Pierce != 9 {
+		t.Errorf("残りの貫通回数 %d, want 9", b.Pierce)
+	}
+}
+
+func TestCollisionResponsesFollowMasks(t *testing.T) {
+	// マスクで有効にしたときだけ判定される組
+	optional := map[[2]CollisionLayer]bool{
+		{layerPlayerShot, layerPlayer}: true,
+		{layerPlayerShot, layerPickup}: true,
+	}
+	for pair, response := range collisionResponses {
+		if response.Handle == nil {
+			t.Errorf("%v: 応答処理がない", pair)
+		}
+		if enabled := layerMasks[pair[0]]&pair[1] != 0; enabled == optional[pair] {
+			t.Errorf("%v: 既定のマスクで判定される = %v", pair, enabled)
+		}
+	}
+	if targetedLayers()&layerPlayerShot != 0 {
+		t.Error("プレイヤーの弾が衝突応答の対象になっている")
+	}
+}
+
+func TestPlayerShotMaskByMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		rules RoomRules
+		want  CollisionLayer
+	}{
+		{"協力プレイ", modeCoop, defaultRules, layerEnemy | layerBoss},
+		{"フレンドリーファイア", modeCoop, RoomRules{MaxPlayers: defaultMaxPlayers, FirePower: 1, FriendlyFire: true}, layerEnemy | layerBoss | layerPlayer},
+		{"デスマッチ", modeDeathmatch, defaultRules, layerEnemy | layerBoss | layerPlayer},
+		{"チーム対戦", modeVersus, defaultRules, layerEnemy | layerBoss | layerPickup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameRoom := newGameRoom(defaultDifficulty, tt.mode, tt.rules)
+			p := testPlayer(gameRoom, "p1", 400, 500)
+			b, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, -6, 5, 10, 1, 0)
+			if b.Layer != layerPlayerShot || b.Mask != tt.want {
+				t.Errorf("レイヤー %b・マスク %b, want %b・%b", b.Layer, b.Mask, layerPlayerShot, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayersWithoutResponseIgnoreEachOther(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 100)
+	ally := testPlayer(gameRoom, "p2", 100, 100)
+	e := stillEnemy(gameRoom, "tank", 300, 100)
+
+	// 敵弾は敵に、協力プレイの自弾は味方に当たらない
+	spawnBossBullet(gameRoom, &Entity{Transform: transformAt(313, 70, 10, 10)}, 0, 0)
+	for _, b := range gameRoom.Bullets {
+		placeBullet(b, 313, 115)
+	}
+	shot, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, 0, 5, 10, 1, 0)
+	placeBullet(shot, ally.X+13, ally.Y+10)
+
+	resolveCollisions(gameRoom)
+	gameRoom.entities.Flush()
+
+	if len(gameRoom.Bullets) != 2 {
+		t.Errorf("弾が %d 発に減った（応答のない組で消えた）", len(gameRoom.Bullets))
+	}
+	if e.Health.Current != e.Health.Max || ally.Health.Current != maxPlayerHealth {
+		t.Errorf("応答のない組でダメージ: 敵 %d・味方 %d", e.Health.Current, ally.Health.Current)
+	}
+}
+
+func TestPickupUsesBoundingBox(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 100)
+	p.FirePower = 1
+
+	// 喰らい判定（機体中央の小さな円）からは外れるが、機体の矩形には重なるアイテム
+	item, c := gameRoom.entities.Spawn()
+	item.Type = "item"
+	item.Transform = transformAt(p.X-10, p.Y-10, 15, 15)
+	item.Velocity = c.Velocity(Velocity{})
+	item.Collider = c.Collider(Collider{})
+	item.Pickup = c.Pickup(Pickup{Item: itemWeapon})
+	setLayer(item, layerPickup)
+	gameRoom.Items[item.ID] = item
+
+	if checkCollision(item, &p.Entity) {
+		t.Fatal("アイテムが喰らい判定と重なっている（矩形判定の検証にならない）")
+	}
+	resolveCollisions(gameRoom)
+	gameRoom.entities.Flush()
+	if len(gameRoom.Items) != 0 || p.FirePower != 2 {
+		t.Errorf("機体の矩形に触れたアイテムを取れない（武器レベル %d）", p.FirePower)
+	}
+}
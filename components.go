@@ -0,0 +1,141 @@
+/**
+ * @file components.go
+ * @description エンティティを構成するコンポーネントの定義
+ *
+ * 概要:
+ * - エンティティはIDと種類に、必要なコンポーネントだけを組み合わせて作る
+ * - Transform 以外のコンポーネントはポインタで持ち、nilなら「持たない」ことを表す
+ * - コンポーネントは埋め込みのため、フィールドはエンティティから直接参照でき、
+ *   JSONでもエンティティのフィールドとして平坦に出力される
+ * - 振る舞いは systems.go のシステムが、コンポーネントの有無で対象を選んで処理する
+ */
+
+package main
+
+/**
+ * 位置と大きさのコンポーネント（全エンティティが持つ）
+ * @property {float64} X - X座標位置
+ * @property {float64} Y - Y座標位置
+ * @property {int} Width - 幅（ピクセル）
+ * @property {int} Height - 高さ（ピクセル）
+ * @property {float64} prevX - 前ティックのX座標（連続衝突判定用）
+ * @property {float64} prevY - 前ティックのY座標（連続衝突判定用）
+ */
+type Transform struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	prevX  float64
+	prevY  float64
+}
+
+/**
+ * 指定位置・大きさの Transform を作成する（前ティックの位置は現在位置で初期化）
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @param {int} width - 幅
+ * @param {int} height - 高さ
+ * @returns {Transform} - 作成された Transform
+ */
+func transformAt(x, y float64, width, height int) Transform {
+	return Transform{X: x, Y: y, Width: width, Height: height, prevX: x, prevY: y}
+}
+
+/**
+ * 速度のコンポーネント（持つエンティティは移動システムで動く）
+ * @property {float64} VelocityX - X方向の速度
+ * @property {float64} VelocityY - Y方向の速度
+ * @property {float64} Scale - 速度倍率（0なら等倍、スピードアップ効果などで設定）
+ * @property {bool} Confined - trueなら画面内に留める（プレイヤー機体）
+ * @property {float64} KnockbackX - ノックバックによるX方向の移動量（毎ティック減衰）
+ * @property {float64} KnockbackY - ノックバックによるY方向の移動量（毎ティック減衰）
+ */
+type Velocity struct {
+	VelocityX float64 `json:"velocityX"`
+	VelocityY float64 `json:"velocityY"`
+	Scale     float64 `json:"-"`
+	Confined  bool    `json:"-"`
+
+	KnockbackX float64 `json:"-"`
+	KnockbackY float64 `json:"-"`
+}
+
+/**
+ * 衝突判定のコンポーネント
+ * @property {*Shape} Shape - 衝突形状（nilなら Width/Height の矩形）
+ * @property {CollisionLayer} Layer - 所属する衝突レイヤー
+ * @property {CollisionLayer} Mask - 衝突対象のレイヤー
+ * @property {int} Damage - 衝突相手に与えるダメージ（弾の威力、敵・ボスの接触ダメージ）
+ * @property {int} Pierce - 残りの貫通回数（0なら次の命中で消滅、弾用）
+ * @property {string} Owner - 弾を発射したプレイヤーのID（得点の帰属先、敵の弾は空）
+ * @property {bool} Fast - 高速な弾か（trueなら移動経路で連続衝突判定）
+ * @property {bool} grazed - グレイズ済みか（敵弾用）
+ * @property {[]EntityID} hits - 既に命中した標的のハンドル（同じ標的への多重ヒット防止）
+ * @property {[]string} hitPlayers - 既に命中したプレイヤーのID（プレイヤーはプールのハンドルを持たないため別に記録）
+ * @property {uint32} queryStamp - グリッド検索での重複排除用スタンプ
+ */
+type Collider struct {
+	Shape      *Shape         `json:"-"`
+	Layer      CollisionLayer `json:"-"`
+	Mask       CollisionLayer `json:"-"`
+	Damage     int            `json:"damage,omitempty"`
+	Pierce     int            `json:"pierce,omitempty"`
+	Owner      string         `json:"owner,omitempty"`
+	Fast       bool           `json:"-"`
+	grazed     bool
+	hits       []EntityID
+	hitPlayers []string
+	queryStamp uint32
+}
+
+/**
+ * 体力のコンポーネント
+ * @property {int} Current - 現在の体力
+ * @property {int} Max - 最大体力（体力バー表示と回復の上限）
+ * @property {[]string} attackers - ダメージを与えたプレイヤーのID（アシスト判定用）
+ * @property {[]hitCooldown} cooldowns - 発生元ごとの被ダメージのクールダウン
+ */
+type Health struct {
+	Current   int `json:"health"`
+	Max       int `json:"maxHealth,omitempty"`
+	attackers []string
+	cooldowns []hitCooldown
+}
+
+/**
+ * 武器のコンポーネント
+ * @property {string} Name - 装備中の武器名
+ * @property {int} FirePower - 武器のレベル（アイテム取得で増加、上限あり）
+ * @property {int} Charge - 前回の発射からの経過ティック数（連射間隔とチャージに使用）
+ */
+type Weapon struct {
+	Name      string `json:"weapon"`
+	FirePower int    `json:"firePower"`
+	Charge    int    `json:"charge"`
+}
+
+/**
+ * 自律行動のコンポーネント
+ * @property {func(*GameRoom, *Entity)} Update - 毎ティック呼ばれる行動処理（ロック済みで呼ばれる）
+ */
+type AI struct {
+	Update func(gameRoom *GameRoom, e *Entity) `json:"-"`
+}
+
+/**
+ * 寿命のコンポーネント
+ * 持つエンティティは画面外に出ると消滅する
+ * @property {int} Ticks - 残りティック数（0なら画面外に出るまで無期限）
+ */
+type Lifetime struct {
+	Ticks int `json:"-"`
+}
+
+/**
+ * アイテムのコンポーネント
+ * @property {string} Item - 取得時に適用するアイテム種類
+ */
+type Pickup struct {
+	Item string `json:"item"`
+}
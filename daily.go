@@ -0,0 +1,84 @@
+/**
+ * @file daily.go
+ * @description デイリーチャレンジ（日替わりのタイムアタック）
+ *
+ * 概要:
+ * - その日（UTC）のルームは全て同じシード値で敵の出現（種類・位置・速度）を決める
+ * - 敵・ボスの攻撃とアイテムのドロップも、出現とは別の系列のその日のシード値で決める
+ *   （攻撃・撃破の回数が変わっても出現の並びはずれない）
+ * - 敵は実時間ではなくティック数で一定間隔に出現するため、どのルームでも同じ順番・タイミングになる
+ * - 難易度はノーマル固定。レベル1のボスを倒すとクリア
+ * - クリアタイムはルーム開始（リスタート）からクリアまでのティック数で測り、日ごとのリーダーボードで速い順に並べる
+ */
+
+package main
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// デイリーチャレンジで敵が出現する間隔（ティック）
+const dailySpawnTicks = 120
+
+/**
+ * チャレンジの日付を取得する
+ * @param {time.Time} t - 時刻
+ * @returns {string} - UTCの日付（YYYY-MM-DD）
+ */
+func challengeDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// 乱数の系列（敵の出現 / 攻撃・ドロップ）
+const (
+	seedSpawn  = "spawn"
+	seedCombat = "combat"
+)
+
+/**
+ * 日付と系列からシード値を求める
+ * @param {string} day - 日付（YYYY-MM-DD）
+ * @param {string} stream - 乱数の系列
+ * @returns {int64} - シード値
+ */
+func dailySeed(day, stream string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("daily:" + stream + ":" + day))
+	return int64(h.Sum64())
+}
+
+/**
+ * その日のチャレンジを始める（ルーム作成時・リスタート時）
+ * 日付を更新し、出現と攻撃・ドロップの乱数をその日のシード値で初期化する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func startDaily(gameRoom *GameRoom) {
+	gameRoom.Day = challengeDay(time.Now())
+	gameRoom.rng = rand.New(rand.NewSource(dailySeed(gameRoom.Day, seedSpawn)))
+	gameRoom.combatRng = rand.New(rand.NewSource(dailySeed(gameRoom.Day, seedCombat)))
+}
+
+/**
+ * ルーム開始からの経過時間を取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {int} - 経過時間（ミリ秒、ティック数から換算）
+ */
+func runMillis(gameRoom *GameRoom) int {
+	return gameRoom.runTicks * 1000 / 60
+}
+
+/**
+ * デイリーチャレンジシステム: 一定ティックごとに敵を出現させ、規定数を倒したらボスを出現させる
+ */
+func dailySystem(gameRoom *GameRoom) {
+	if !roomMode(gameRoom).Daily || gameRoom.BossSpawned || gameRoom.runTicks%dailySpawnTicks != 0 {
+		return
+	}
+	if gameRoom.EnemiesDefeated >= currentLevel(gameRoom).EnemiesToBoss {
+		spawnBosses(gameRoom)
+		return
+	}
+	spawnEnemy(gameRoom, pickEnemyArchetype(gameRoom), float64(gameRoom.rng.Intn(600)))
+}
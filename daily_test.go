@@ -0,0 +1,137 @@
+/**
+ * @file daily_test.go
+ * @description デイリーチャレンジのテスト
+ *
+ * 概要:
+ * - 同じ日のルームで敵の出現・攻撃・ドロップの乱数が一致することを確認する
+ * - 攻撃・ドロップの乱数を使っても出現の並びがずれないことを確認する
+ * - 同じ操作をした2つのルームで、敵弾とドロップが同じ並びになることを確認する
+ * - ボス体力・発射確率が参加人数で変わらないことを確認する
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+/**
+ * デイリーチャレンジのルームで一定ティックの間に出現した敵を記録する
+ * @param {int} combatDraws - ティックごとに攻撃・ドロップの乱数を引く回数（プレイヤーの行動の違いを模す）
+ * @returns {[]string} - 出現した敵（出現順）
+ */
+func dailySpawns(combatDraws int) []string {
+	gameRoom := newGameRoom(defaultDifficulty, modeDaily, defaultRules)
+	var spawns []string
+	for tick := 0; tick < dailySpawnTicks*10; tick++ {
+		gameRoom.runTicks = tick
+		before := len(gameRoom.Enemies)
+		known := make(map[EntityID]bool, before)
+		for id := range gameRoom.Enemies {
+			known[id] = true
+		}
+		dailySystem(gameRoom)
+		for id, e := range gameRoom.Enemies {
+			if !known[id] {
+				spawns = append(spawns, fmt.Sprint(e.Kind, e.X, e.VelocityX, e.VelocityY))
+			}
+		}
+		for i := 0; i < combatDraws; i++ {
+			rollDrop(gameRoom.combatRng, "grunt")
+		}
+	}
+	return spawns
+}
+
+func TestDailySpawnsMatchAcrossRooms(t *testing.T) {
+	a, b := dailySpawns(0), dailySpawns(7)
+	if len(a) != 10 {
+		t.Fatalf("出現数が %d", len(a))
+	}
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Fatalf("攻撃・ドロップの乱数で出現の並びがずれた:\n%v\n%v", a, b)
+	}
+}
+
+func TestDailyCombatRngMatchesAcrossRooms(t *testing.T) {
+	a := newGameRoom(defaultDifficulty, modeDaily, defaultRules)
+	b := newGameRoom(defaultDifficulty, modeDaily, defaultRules)
+	for i := 0; i < 100; i++ {
+		if x, y := rollDrop(a.combatRng, "tank"), rollDrop(b.combatRng, "tank"); x != y {
+			t.Fatalf("%d 回目のドロップが違う: %q / %q", i, x, y)
+		}
+	}
+	if dailySeed(a.Day, seedSpawn) == dailySeed(a.Day, seedCombat) {
+		t.Fatal("出現と攻撃・ドロップが同じシード値")
+	}
+}
+
+/**
+ * デイリーチャレンジのルームを、決まった操作で一定ティック進めて敵弾とアイテムの生成を記録する
+ * @param {int} ticks - 進めるティック数
+ * @returns {[]string} - 生成された敵弾・アイテム（生成順）
+ */
+func dailyScript(ticks int) []string {
+	gameRoom := newGameRoom(defaultDifficulty, modeDaily, defaultRules)
+	p := testPlayer(gameRoom, "p1", 0, worldHeight-60)
+	var events []string
+	var last uint64
+	for tick := 0; tick < ticks; tick++ {
+		p.X = float64(tick * 7 % (worldWidth - 30)) // 左右に往復しながら撃ち続ける
+		p.Invulnerable = 60
+		createBullet(gameRoom, p)
+		updateGame(gameRoom)
+
+		var spawned []*Entity
+		for _, entities := range []map[EntityID]*Entity{gameRoom.Bullets, gameRoom.Items} {
+			for _, e := range entities {
+				if e.seq > last && e.Type != "bullet" {
+					spawned = append(spawned, e)
+				}
+			}
+		}
+		sortBySpawn(spawned)
+		for _, e := range spawned {
+			events = append(events, fmt.Sprint(tick, e.Type, e.Kind, e.Pickup, e.X, e.Y))
+		}
+		last = gameRoom.entities.spawned
+	}
+	return events
+}
+
+func TestDailyCombatMatchesAcrossRooms(t *testing.T) {
+	a, b := dailyScript(3000), dailyScript(3000)
+	items := 0
+	for _, e := range a {
+		if strings.Contains(e, "item") {
+			items++
+		}
+	}
+	if len(a) == 0 || items == 0 {
+		t.Fatalf("敵弾・ドロップが生成されていない（%d 件、アイテム %d 件）", len(a), items)
+	}
+	for i := range a {
+		if i >= len(b) || a[i] != b[i] {
+			t.Fatalf("%d 件目が違う:\n%v\n%v", i, a[i], b[min(i, len(b)-1)])
+		}
+	}
+	if len(a) != len(b) {
+		t.Fatalf("件数が違う: %d / %d", len(a), len(b))
+	}
+}
+
+func TestDailyScalingIgnoresPlayers(t *testing.T) {
+	for _, mode := range []string{modeDaily, modeCoop} {
+		gameRoom := newGameRoom(defaultDifficulty, mode, defaultRules)
+		testPlayer(gameRoom, "p1", 100, 500)
+		fire, health := enemyFireChance(gameRoom), scaledBossHealth(gameRoom, 100)
+		testPlayer(gameRoom, "p2", 200, 500)
+		testPlayer(gameRoom, "p3", 300, 500)
+		same := enemyFireChance(gameRoom) == fire && scaledBossHealth(gameRoom, 100) == health
+		if want := mode == modeDaily; same != want {
+			t.Errorf("%s: 3人でも補正が同じ = %v, want %v", mode, same, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/**
+ * @file damage.go
+ * @description プレイヤーへのダメージの無敵時間・クールダウン・ノックバック
+ *
+ * 概要:
+ * - ダメージの扱いは発生元のレイヤーごとに damageRules に登録する
+ * - 被弾後は一定時間無敵になり、その間の被弾は無視する
+ * - 体当たりなど接触し続ける発生元は、発生元ごとのクールダウンが明けるまで再びダメージを与えない
+ *   （ボスに触れ続けても毎ティック体力が減ることはない）
+ * - 接触ダメージは発生元から離れる向きに押し返す（無敵中も押し返して重なりを解消する）
+ */
+
+package main
+
+import "math"
+
+/**
+ * ダメージ規則構造体
+ * @property {int} Cooldown - 同じ発生元から再びダメージを受けるまでのティック数（0なら制限なし）
+ * @property {float64} Knockback - 押し返す初速（0なら押し返さない）
+ * @property {int} IFrames - 被弾後の無敵ティック数
+ * @property {int} Scale - 発生元の Damage にかける倍率（0なら1倍）
+ */
+type DamageRule struct {
+	Cooldown  int
+	Knockback float64
+	IFrames   int
+	Scale     int
+}
+
+// 発生元レイヤーごとのダメージ規則
+var damageRules = map[CollisionLayer]DamageRule{
+	layerEnemyShot: {IFrames: 30},
+	layerEnemy:     {Knockback: 6, IFrames: 30}, // ダメージを与えた雑魚敵は消滅するのでクールダウンは不要
+	layerBoss:      {Cooldown: 60, Knockback: 10, IFrames: 45},
+	// プレイヤーの弾（デスマッチ・フレンドリーファイア、敵向けのダメージのままでは倒しきれないため倍率をかける）
+	layerPlayerShot: {IFrames: 20, Scale: 10},
+}
+
+// ノックバックの減衰率（ティックごと）
+const knockbackDecay = 0.8
+
+/**
+ * 発生元ごとのクールダウン構造体
+ * @property {EntityID} Source - 発生元のハンドル
+ * @property {int} Ticks - 残りティック数
+ */
+type hitCooldown struct {
+	Source EntityID
+	Ticks  int
+}
+
+/**
+ * 発生元がクールダウン中か
+ * @param {*Health} h - ダメージを受ける側の体力
+ * @param {EntityID} source - 発生元のハンドル
+ * @returns {bool} - クールダウン中ならtrue
+ */
+func onCooldown(h *Health, source EntityID) bool {
+	for _, c := range h.cooldowns {
+		if c.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 発生元から離れる向きに押し返す
+ * @param {*Entity} e - 押し返されるエンティティ
+ * @param {*Entity} source - 発生元
+ * @param {float64} speed - 押し返す初速
+ */
+func applyKnockback(e *Entity, source *Entity, speed float64) {
+	dx := (e.X + float64(e.Width)/2) - (source.X + float64(source.Width)/2)
+	dy := (e.Y + float64(e.Height)/2) - (source.Y + float64(source.Height)/2)
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		dx, dy, length = 0, 1, 1 // 中心が重なっていたら下へ
+	}
+	e.KnockbackX = dx / length * speed
+	e.KnockbackY = dy / length * speed
+}
+
+/**
+ * ダメージシステム: 無敵時間と発生元ごとのクールダウンを進める
+ */
+func damageSystem(gameRoom *GameRoom) {
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.Health == nil {
+			return
+		}
+		live := e.cooldowns[:0]
+		for _, c := range e.cooldowns {
+			if c.Ticks--; c.Ticks > 0 {
+				live = append(live, c)
+			}
+		}
+		e.cooldowns = live
+	})
+	for _, p := range gameRoom.Players {
+		if p.Invulnerable > 0 {
+			p.Invulnerable--
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/**
+ * @file damage_test.go
+ * @description プレイヤーへのダメージのテスト
+ *
+ * 概要:
+ * - 雑魚敵の体当たりは、ダメージを与えたときだけ敵が消滅することを確認する
+ */
+
+package main
+
+import "testing"
+
+func TestEnemyRamsPlayer(t *testing.T) {
+	tests := []struct {
+		name         string
+		invulnerable int
+		shield       bool
+		wantDamage   bool
+	}{
+		{"ダメージを与える", 0, false, true},
+		{"無敵時間中", 10, false, false},
+		{"シールド中", 0, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+			p := &Player{
+				Entity: Entity{
+					Type:      "player",
+					Transform: transformAt(100, 100, 30, 30),
+					Collider:  &Collider{Shape: playerHurtbox},
+					Velocity:  &Velocity{},
+					Health:    &Health{Current: maxPlayerHealth, Max: maxPlayerHealth},
+				},
+				ID:           "ram",
+				Lives:        startingLives,
+				Invulnerable: tt.invulnerable,
+				Effects:      make(map[string]int),
+			}
+			if tt.shield {
+				p.Effects[itemShield] = 60
+			}
+			setLayer(&p.Entity, layerPlayer)
+			gameRoom.Players[p.ID] = p
+
+			e, c := gameRoom.entities.Spawn()
+			e.Type = "enemy"
+			e.Transform = transformAt(100, 90, 20, 20)
+			e.Velocity = c.Velocity(Velocity{})
+			e.Collider = c.Collider(Collider{Damage: 10})
+			e.Health = c.Health(Health{Current: 1, Max: 1})
+			setLayer(e, layerEnemy)
+			gameRoom.Enemies[e.ID] = e
+
+			enemyRamsPlayer(gameRoom, e, &p.Entity)
+			gameRoom.entities.Flush()
+
+			if damaged := p.Health.Current < maxPlayerHealth; damaged != tt.wantDamage {
+				t.Errorf("ダメージを受けた = %v, want %v", damaged, tt.wantDamage)
+			}
+			if removed := gameRoom.entities.Get(e.ID) == nil; removed != tt.wantDamage {
+				t.Errorf("敵が消滅した = %v, want %v", removed, tt.wantDamage)
+			}
+			if p.KnockbackY <= 0 {
+				t.Errorf("押し返されていない: %v", p.KnockbackY)
+			}
+		})
+	}
+}
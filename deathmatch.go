@@ -0,0 +1,206 @@
+/**
+ * @file deathmatch.go
+ * @description デスマッチ（プレイヤー同士の対戦）
+ *
+ * 概要:
+ * - プレイヤーの弾が他のプレイヤーに当たる（自分の弾は当たらない）。敵・ボスは出現しない
+ * - 他のプレイヤーを倒すと撃墜数とスコアが加算される
+ * - 倒れたプレイヤーは残機を消費せず、一定時間後に他のプレイヤーから離れた位置でリスポーンする
+ * - 誰かが規定の撃墜数に達するか、制限時間が過ぎると試合終了
+ * - 試合時間は2人以上そろっている間だけ進む（相手を待つ間に時間切れで勝つことはない）
+ * - 試合終了時は順位付きの結果をゲーム状態に含めて全員に送る
+ */
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// 試合終了となる撃墜数
+	fragLimit = 10
+	// 試合の制限時間（ティック）
+	matchDurationTicks = 3 * 60 * 60
+	// リスポーン位置の候補数（他のプレイヤーから最も離れた候補を選ぶ）
+	spawnCandidates = 8
+)
+
+// 試合終了の理由
+const (
+	matchEndFragLimit = "fragLimit" // 撃墜数に達した
+	matchEndTime      = "time"      // 制限時間が過ぎた
+)
+
+/**
+ * 試合のプレイヤーごとの結果構造体
+ * @property {string} PlayerID - プレイヤーID
+ * @property {string} Name - プレイヤー名
+ * @property {string} Color - プレイヤーの色
+ * @property {int} Frags - 撃墜数
+ * @property {int} Deaths - 倒された回数
+ * @property {int} Score - スコア
+ */
+type MatchPlayerResult struct {
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Frags    int    `json:"frags"`
+	Deaths   int    `json:"deaths"`
+	Score    int    `json:"score"`
+}
+
+/**
+ * 試合結果構造体
+ * @property {string} Reason - 終了の理由（"fragLimit", "time"）
+ * @property {int} Seconds - 試合時間（2人以上そろっていた秒数）
+ * @property {string} Winner - 優勝したプレイヤーのID（同率1位・1人だけの試合なら空）
+ * @property {[]MatchPlayerResult} Players - 順位順の結果（撃墜数が多い順、同数なら倒された回数が少ない順）
+ */
+type MatchResult struct {
+	Reason  string              `json:"reason"`
+	Seconds int                 `json:"seconds"`
+	Winner  string              `json:"winner"`
+	Players []MatchPlayerResult `json:"players"`
+}
+
+/**
+ * 試合の状況構造体（ゲーム状態に含めて送る）
+ * @property {int} TimeLeft - 残り秒数
+ * @property {int} FragLimit - 試合終了となる撃墜数
+ * @property {bool} Waiting - 対戦相手の参加を待っているか（試合時間が止まっている）
+ * @property {*MatchResult} Results - 試合結果（試合中はnil）
+ */
+type MatchStatus struct {
+	TimeLeft  int          `json:"timeLeft"`
+	FragLimit int          `json:"fragLimit"`
+	Waiting   bool         `json:"waiting"`
+	Results   *MatchResult `json:"results"`
+}
+
+/**
+ * 衝突応答: プレイヤーの弾が他のプレイヤーに命中（PvPのモードのみ、マスクで有効になる）
+ */
+func shotHitsRival(gameRoom *GameRoom, b, target *Entity) bool {
+	p := playerByEntity(gameRoom, target)
+	if p == nil || p.ID == b.Owner || !isAlive(p) || !registerPlayerHit(gameRoom, b, p) {
+		return true
+	}
+	damagePlayer(gameRoom, p, b)
+	return true
+}
+
+/**
+ * 撃墜を記録する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} victim - 倒されたプレイヤー
+ * @param {string} killer - とどめを刺した弾の持ち主のID（退出済み・敵の攻撃なら加算しない）
+ */
+func recordFrag(gameRoom *GameRoom, victim *Player, killer string) {
+	victim.Deaths++
+	if p, ok := gameRoom.Players[killer]; ok && p != victim {
+		p.Frags++
+		awardScore(gameRoom, p, scorePoints[scoreFrag], scoreFrag)
+	}
+}
+
+/**
+ * 他のプレイヤーから離れたランダムな位置に移動する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー
+ */
+func moveToArenaSpawn(gameRoom *GameRoom, p *Player) {
+	bestX, bestY, best := 0.0, 0.0, -1.0
+	for i := 0; i < spawnCandidates; i++ {
+		x := rand.Float64() * (worldWidth - float64(p.Width))
+		y := rand.Float64() * (worldHeight - float64(p.Height))
+		nearest := math.MaxFloat64
+		for _, q := range gameRoom.Players {
+			if q != p && isAlive(q) {
+				nearest = math.Min(nearest, math.Hypot(q.X-x, q.Y-y))
+			}
+		}
+		if nearest > best {
+			bestX, bestY, best = x, y, nearest
+		}
+	}
+	p.X, p.Y = bestX, bestY
+	p.prevX, p.prevY = p.X, p.Y
+}
+
+/**
+ * 試合システム: PvPのモードで試合時間を進め、撃墜数と制限時間を確認して試合を終了する
+ * 対戦相手がそろうまで試合時間は進めない
+ */
+func matchSystem(gameRoom *GameRoom) {
+	if !roomMode(gameRoom).PvP {
+		return
+	}
+	if len(gameRoom.Players) < minPvPPlayers {
+		return
+	}
+	gameRoom.matchTicks++
+	for _, p := range gameRoom.Players {
+		if p.Frags >= fragLimit {
+			endMatch(gameRoom, matchEndFragLimit)
+			return
+		}
+	}
+	if gameRoom.matchTicks >= matchDurationTicks {
+		endMatch(gameRoom, matchEndTime)
+	}
+}
+
+/**
+ * 試合を終了し、結果を記録する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {string} reason - 終了の理由
+ */
+func endMatch(gameRoom *GameRoom, reason string) {
+	result := &MatchResult{Reason: reason, Seconds: gameRoom.matchTicks / 60}
+	for _, p := range gameRoom.Players {
+		result.Players = append(result.Players, MatchPlayerResult{
+			PlayerID: p.ID,
+			Name:     p.Name,
+			Color:    p.Color,
+			Frags:    p.Frags,
+			Deaths:   p.Deaths,
+			Score:    p.Score,
+		})
+	}
+	sort.SliceStable(result.Players, func(i, j int) bool {
+		a, b := result.Players[i], result.Players[j]
+		if a.Frags != b.Frags {
+			return a.Frags > b.Frags
+		}
+		return a.Deaths < b.Deaths
+	})
+	if top := result.Players; len(top) > 1 && (top[0].Frags != top[1].Frags || top[0].Deaths != top[1].Deaths) {
+		result.Winner = top[0].PlayerID
+	}
+
+	gameRoom.GameState = "matchover"
+	gameRoom.matchResult = result
+	submitScores(gameRoom)
+	recordProfileStats(gameRoom)
+}
+
+/**
+ * 試合の状況を取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {*MatchStatus} - 試合の状況（PvPのモードでなければnil）
+ */
+func matchStatus(gameRoom *GameRoom) *MatchStatus {
+	if !roomMode(gameRoom).PvP {
+		return nil
+	}
+	left := (matchDurationTicks - gameRoom.matchTicks + 59) / 60
+	return &MatchStatus{
+		TimeLeft:  max(left, 0),
+		FragLimit: fragLimit,
+		Waiting:   gameRoom.matchResult == nil && len(gameRoom.Players) < minPvPPlayers,
+		Results:   gameRoom.matchResult,
+	}
+}
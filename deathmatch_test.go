@@ -0,0 +1,108 @@
+/**
+ * @file deathmatch_test.go
+ * @description デスマッチ（プレイヤー同士の対戦）のテスト
+ *
+ * 概要:
+ * - 貫通する弾が経路上の複数のプレイヤーにそれぞれ命中することを確認する
+ * - 試合時間は対戦相手がそろうまで進まず、1人だけの試合に勝者がいないことを確認する
+ */
+
+package main
+
+import "testing"
+
+/**
+ * テスト用のプレイヤーを作成してルームに追加する（参加時と同じコンポーネントを持つ）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {string} id - プレイヤーID
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @returns {*Player} - プレイヤー
+ */
+func testPlayer(gameRoom *GameRoom, id string, x, y float64) *Player {
+	p := &Player{
+		Entity: Entity{
+			Type:      "player",
+			Transform: transformAt(x, y, 30, 30),
+			Velocity:  &Velocity{Confined: true},
+			Collider:  &Collider{Shape: playerHurtbox},
+			Health:    &Health{Current: maxPlayerHealth, Max: maxPlayerHealth},
+			Weapon:    &Weapon{Name: defaultWeapon, FirePower: 1},
+		},
+		ID:      id,
+		Name:    id,
+		Lives:   startingLives,
+		Effects: make(map[string]int),
+	}
+	setLayer(&p.Entity, layerPlayer)
+	gameRoom.Players[p.ID] = p
+	return p
+}
+
+func TestPiercingShotHitsEveryPlayer(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		rules RoomRules
+	}{
+		{"デスマッチ", modeDeathmatch, defaultRules},
+		{"フレンドリーファイア", modeCoop, RoomRules{MaxPlayers: defaultMaxPlayers, FirePower: 1, FriendlyFire: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameRoom := newGameRoom(defaultDifficulty, tt.mode, tt.rules)
+			shooter := testPlayer(gameRoom, "shooter", 400, 100)
+			near := testPlayer(gameRoom, "near", 400, 200)
+			far := testPlayer(gameRoom, "far", 400, 300)
+
+			// 止まっている細長い弾が2人を同時に貫く（貫通回数1なら2人目で消える）
+			b, _ := spawnPlayerBullet(gameRoom, shooter, weaponLaser, 0, 0, 0, 4, 240, 1, 1)
+			updateGame(gameRoom)
+
+			for _, p := range []*Player{near, far} {
+				if p.Health.Current >= maxPlayerHealth {
+					t.Errorf("%s に命中していない（体力 %d）", p.ID, p.Health.Current)
+				}
+			}
+			if shooter.Health.Current != maxPlayerHealth {
+				t.Errorf("自分の弾が当たった（体力 %d）", shooter.Health.Current)
+			}
+			if gameRoom.entities.Get(b.ID) != nil {
+				t.Error("貫通回数が尽きた弾が残っている")
+			}
+		})
+	}
+}
+
+func TestMatchClockWaitsForOpponent(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeDeathmatch, defaultRules)
+	solo := testPlayer(gameRoom, "solo", 100, 100)
+	for i := 0; i < matchDurationTicks+60; i++ {
+		updateGame(gameRoom)
+	}
+	if gameRoom.GameState != "playing" {
+		t.Fatalf("1人のまま試合が終わった: %s（勝者 %q）", gameRoom.GameState, gameRoom.matchResult.Winner)
+	}
+	if status := matchStatus(gameRoom); !status.Waiting || status.TimeLeft != matchDurationTicks/60 {
+		t.Fatalf("待機中の試合の状況 %+v", status)
+	}
+
+	// 相手がそろってから制限時間が過ぎると終了する
+	testPlayer(gameRoom, "rival", 600, 500)
+	for i := 0; i < matchDurationTicks && gameRoom.GameState == "playing"; i++ {
+		updateGame(gameRoom)
+	}
+	if gameRoom.GameState != "matchover" || gameRoom.matchResult.Seconds != matchDurationTicks/60 {
+		t.Fatalf("制限時間で終了しない: %s %+v", gameRoom.GameState, gameRoom.matchResult)
+	}
+	if gameRoom.matchResult.Winner != "" {
+		t.Fatalf("同点の試合で勝者 %q", gameRoom.matchResult.Winner)
+	}
+
+	// 1人だけで終わった試合に勝者はいない
+	delete(gameRoom.Players, "rival")
+	endMatch(gameRoom, matchEndTime)
+	if gameRoom.matchResult.Winner != "" {
+		t.Fatalf("1人だけの試合で %s が勝者になった", solo.ID)
+	}
+}
@@ -0,0 +1,149 @@
+/**
+ * @file difficulty.go
+ * @description 難易度設定とプレイヤー数による難易度スケーリング
+ *
+ * 概要:
+ * - ルーム作成時に選択する難易度（easy / normal / hard / nightmare）
+ * - ボス体力・敵の出現間隔・敵の発射確率を難易度と参加人数で補正
+ * - デイリーチャレンジは記録を比べられるよう、人数によらず1人分の補正で固定する
+ */
+
+package main
+
+import (
+	"math"
+	"time"
+)
+
+/**
+ * 難易度構造体
+ * 各パラメータへの倍率を保持
+ * @property {string} Name - 難易度名
+ * @property {float64} BossHealth - ボス体力の倍率
+ * @property {float64} SpawnRate - 敵出現頻度の倍率（大きいほど頻繁）
+ * @property {float64} EnemyFire - 敵の発射確率の倍率
+ */
+type Difficulty struct {
+	Name       string
+	BossHealth float64
+	SpawnRate  float64
+	EnemyFire  float64
+}
+
+// 既定の難易度名
+const defaultDifficulty = "normal"
+
+// 選択可能な難易度（キー：難易度名）
+var difficulties = map[string]Difficulty{
+	"easy":      {Name: "easy", BossHealth: 0.7, SpawnRate: 0.75, EnemyFire: 0.5},
+	"normal":    {Name: "normal", BossHealth: 1.0, SpawnRate: 1.0, EnemyFire: 1.0},
+	"hard":      {Name: "hard", BossHealth: 1.5, SpawnRate: 1.4, EnemyFire: 1.6},
+	"nightmare": {Name: "nightmare", BossHealth: 2.2, SpawnRate: 1.8, EnemyFire: 2.5},
+}
+
+const (
+	// 敵の基本出現間隔
+	baseEnemySpawnInterval = time.Second * 2
+	// 敵の基本発射確率（1ティックあたり）
+	baseEnemyFireChance = 0.005
+	// 追加プレイヤー1人あたりのボス体力増加率
+	bossHealthPerPlayer = 0.6
+	// 追加プレイヤー1人あたりの敵出現頻度増加率
+	spawnRatePerPlayer = 0.25
+	// 追加プレイヤー1人あたりの敵発射確率増加率
+	enemyFirePerPlayer = 0.15
+)
+
+/**
+ * 難易度名を検証する
+ * 未知の難易度名の場合は既定の難易度を返す
+ * @param {string} name - 難易度名
+ * @returns {string} - 有効な難易度名
+ */
+func normalizeDifficulty(name string) string {
+	if _, ok := difficulties[name]; ok {
+		return name
+	}
+	return defaultDifficulty
+}
+
+/**
+ * ルームの難易度設定を取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @returns {Difficulty} - 難易度設定
+ */
+func roomDifficulty(gameRoom *GameRoom) Difficulty {
+	if d, ok := difficulties[gameRoom.Difficulty]; ok {
+		return d
+	}
+	return difficulties[defaultDifficulty]
+}
+
+/**
+ * 生存中のプレイヤー数を数える（最低1）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {int} - 生存中のプレイヤー数
+ */
+func activePlayerCount(gameRoom *GameRoom) int {
+	count := 0
+	for _, p := range gameRoom.Players {
+		if p.Health.Current > 0 {
+			count++
+		}
+	}
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+/**
+ * 人数補正に使う追加プレイヤー数（生存中の人数-1）
+ * デイリーチャレンジは全員が同じ条件で競うため、常に0
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {float64} - 追加プレイヤー数
+ */
+func extraPlayers(gameRoom *GameRoom) float64 {
+	if roomMode(gameRoom).Daily {
+		return 0
+	}
+	return float64(activePlayerCount(gameRoom) - 1)
+}
+
+/**
+ * 難易度と参加人数（エンドレスモードではウェーブも）で補正したボス体力を計算する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {int} base - ボス定義の基本体力
+ * @returns {int} - 補正後の体力
+ */
+func scaledBossHealth(gameRoom *GameRoom, base int) int {
+	players := extraPlayers(gameRoom)
+	health := float64(base) * roomDifficulty(gameRoom).BossHealth * (1 + bossHealthPerPlayer*players) * waveScale(gameRoom, bossHealthPerWave, 0)
+	return int(math.Max(1, math.Round(health)))
+}
+
+/**
+ * 難易度と参加人数（エンドレスモードではウェーブも）で補正した敵の出現間隔を計算する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @returns {time.Duration} - 次の敵が出現するまでの間隔
+ */
+func enemySpawnInterval(gameRoom *GameRoom) time.Duration {
+	gameRoom.Mutex.Lock()
+	players := extraPlayers(gameRoom)
+	waveRate := waveScale(gameRoom, spawnRatePerWave, maxWaveSpawnRate)
+	gameRoom.Mutex.Unlock()
+
+	rate := roomDifficulty(gameRoom).SpawnRate * (1 + spawnRatePerPlayer*players) * waveRate
+	return time.Duration(float64(baseEnemySpawnInterval) / rate)
+}
+
+/**
+ * 難易度と参加人数で補正した敵の発射確率を計算する
+ * 低い確率でも倍率の差が残るよう、丸めずに確率のまま返す
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {float64} - 1ティックあたりの発射確率（0〜1）
+ */
+func enemyFireChance(gameRoom *GameRoom) float64 {
+	players := extraPlayers(gameRoom)
+	return baseEnemyFireChance * roomDifficulty(gameRoom).EnemyFire * (1 + enemyFirePerPlayer*players)
+}
@@ -0,0 +1,90 @@
+/**
+ * @file difficulty_test.go
+ * @description 難易度と参加人数による補正のテスト
+ *
+ * 概要:
+ * - 敵の発射確率が丸められず、難易度の倍率どおりの比になることを確認する
+ * - ボス体力が難易度と参加人数に応じて増えることを確認する
+ * - 人数補正は生存中のプレイヤーだけを数えることを確認する
+ * - 未知の難易度名が既定の難易度になることを確認する
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestEnemyFireChanceKeepsMultiplier(t *testing.T) {
+	normal := enemyFireChance(newGameRoom("normal", modeCoop, defaultRules))
+	for name, d := range difficulties {
+		got := enemyFireChance(newGameRoom(name, modeCoop, defaultRules))
+		if want := normal * d.EnemyFire; math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s: 発射確率 %v, want %v（ノーマルの %v 倍）", name, got, want, d.EnemyFire)
+		}
+	}
+	if easy := enemyFireChance(newGameRoom("easy", modeCoop, defaultRules)); easy != 0.0025 {
+		t.Errorf("イージーの発射確率 %v, want 0.0025", easy)
+	}
+}
+
+func TestScaledBossHealth(t *testing.T) {
+	tests := []struct {
+		difficulty string
+		players    int
+		want       int
+	}{
+		{"normal", 1, 100},
+		{"easy", 1, 70},
+		{"nightmare", 1, 220},
+		{"normal", 2, 160},
+		{"hard", 3, 330},
+	}
+	for _, tt := range tests {
+		gameRoom := newGameRoom(tt.difficulty, modeCoop, defaultRules)
+		for i := 0; i < tt.players; i++ {
+			testPlayer(gameRoom, fmt.Sprintf("p%d", i), 100, 100)
+		}
+		if got := scaledBossHealth(gameRoom, 100); got != tt.want {
+			t.Errorf("%s・%d人: ボス体力 %d, want %d", tt.difficulty, tt.players, got, tt.want)
+		}
+	}
+}
+
+func TestScalingCountsLivingPlayers(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	testPlayer(gameRoom, "p1", 100, 100)
+	solo := enemySpawnInterval(gameRoom)
+	if solo != baseEnemySpawnInterval {
+		t.Fatalf("1人の出現間隔 %v, want %v", solo, baseEnemySpawnInterval)
+	}
+
+	downed := testPlayer(gameRoom, "p2", 200, 100)
+	if got := enemySpawnInterval(gameRoom); got >= solo {
+		t.Errorf("2人で出現間隔が短くならない: %v", got)
+	}
+	if got := enemyFireChance(gameRoom); got <= baseEnemyFireChance {
+		t.Errorf("2人で発射確率が上がらない: %v", got)
+	}
+
+	// 倒れているプレイヤーは数えない
+	downed.Health.Current = 0
+	if got := enemySpawnInterval(gameRoom); got != solo {
+		t.Errorf("倒れたプレイヤーで出現間隔が変わった: %v, want %v", got, solo)
+	}
+}
+
+func TestNormalizeDifficulty(t *testing.T) {
+	for name := range difficulties {
+		if got := normalizeDifficulty(name); got != name {
+			t.Errorf("normalizeDifficulty(%q) = %q", name, got)
+		}
+	}
+	for _, name := range []string{"", "insane", "Hard"} {
+		if got := normalizeDifficulty(name); got != defaultDifficulty {
+			t.Errorf("normalizeDifficulty(%q) = %q, want %q", name, got, defaultDifficulty)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/**
+ * @file endless.go
+ * @description エンドレス（耐久）モードのウェーブ構成と難易度の上昇
+ *
+ * 概要:
+ * - レベルの代わりにウェーブが続き、ゲームオーバーになるまで終わらない（Level をウェーブ番号として使う）
+ * - ウェーブが進むほど、ウェーブ内の敵の数・出現頻度・落下速度・ボス体力が増える
+ * - ウェーブが進むほど、耐久力のある敵や速い敵の出現比率が上がる
+ * - 一定のウェーブごとにボスが出現し、レベル構成のボスを順番に使う
+ * - 最終スコアは到達したウェーブで、リーダーボードのエンドレスの区分に登録する
+ */
+
+package main
+
+import "math"
+
+const (
+	// ボスが出現するウェーブの間隔
+	bossEveryWaves = 5
+	// 1ウェーブ目に倒す敵の数と、ウェーブごとの増加数
+	waveBaseEnemies    = 10
+	waveEnemiesPerWave = 2
+	// ウェーブごとの敵出現頻度の増加率と上限倍率
+	spawnRatePerWave = 0.1
+	maxWaveSpawnRate = 3.0
+	// ウェーブごとの敵の落下速度の増加率と上限倍率
+	speedPerWave = 0.05
+	maxWaveSpeed = 2.0
+	// ウェーブごとのボス体力の増加率
+	bossHealthPerWave = 0.1
+)
+
+/**
+ * エンドレスモードのウェーブ番号を取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @returns {int} - ウェーブ番号（エンドレスモードでなければ0）
+ */
+func endlessWave(gameRoom *GameRoom) int {
+	if !roomMode(gameRoom).Endless {
+		return 0
+	}
+	return gameRoom.Level
+}
+
+/**
+ * ウェーブ番号に応じた倍率を計算する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {float64} perWave - 1ウェーブごとの増加率
+ * @param {float64} limit - 上限倍率（0なら上限なし）
+ * @returns {float64} - 倍率（エンドレスモードでなければ1）
+ */
+func waveScale(gameRoom *GameRoom, perWave, limit float64) float64 {
+	wave := endlessWave(gameRoom)
+	if wave <= 1 {
+		return 1
+	}
+	scale := 1 + perWave*float64(wave-1)
+	if limit > 0 {
+		scale = math.Min(scale, limit)
+	}
+	return scale
+}
+
+/**
+ * ウェーブの構成を作る
+ * ボスのウェーブでは、レベル構成のボスを順番に使う
+ * @param {int} wave - ウェーブ番号（1始まり）
+ * @returns {Level} - ウェーブの構成（Number はウェーブ番号）
+ */
+func waveLevel(wave int) Level {
+	level := Level{Number: wave, EnemiesToBoss: waveBaseEnemies + waveEnemiesPerWave*(wave-1)}
+	if wave%bossEveryWaves == 0 {
+		level.Bosses = levels[(wave/bossEveryWaves-1)%len(levels)].Bosses
+	}
+	return level
+}
+
+/**
+ * ウェーブシステム: ボスのいないウェーブで規定数の敵を倒したら次のウェーブへ進める
+ * ボスのウェーブはボスを全て倒したときに進む（defeatBoss）
+ */
+func waveSystem(gameRoom *GameRoom) {
+	if endlessWave(gameRoom) == 0 {
+		return
+	}
+	level := currentLevel(gameRoom)
+	if len(level.Bosses) == 0 && gameRoom.EnemiesDefeated >= level.EnemiesToBoss {
+		levelCleared(gameRoom)
+	}
+}
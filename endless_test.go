@@ -0,0 +1,126 @@
+/**
+ * @file endless_test.go
+ * @description エンドレスモードのウェーブ構成と難易度の上昇のテスト
+ *
+ * 概要:
+ * - ウェーブごとの敵の数と、一定ウェーブごとにレベル構成のボスが順番に出ることを確認する
+ * - 規定数の敵を倒すと次のウェーブへ進み、ボスのウェーブはボスを倒すまで進まないことを確認する
+ * - 最終レベルの数を超えてもクリアにならずにウェーブが続くことを確認する
+ * - ウェーブによる倍率が上限で止まり、エンドレス以外では1であることを確認する
+ * - ウェーブが進むほど耐久力のある敵や速い敵が増えることを確認する
+ */
+
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestWaveLevel(t *testing.T) {
+	tests := []struct {
+		wave    int
+		enemies int
+		bosses  []string
+	}{
+		{1, waveBaseEnemies, nil},
+		{4, waveBaseEnemies + 3*waveEnemiesPerWave, nil},
+		{5, waveBaseEnemies + 4*waveEnemiesPerWave, levels[0].Bosses},
+		{10, waveBaseEnemies + 9*waveEnemiesPerWave, levels[1].Bosses},
+		{15, waveBaseEnemies + 14*waveEnemiesPerWave, levels[2].Bosses},
+		{20, waveBaseEnemies + 19*waveEnemiesPerWave, levels[0].Bosses},
+	}
+	for _, tt := range tests {
+		level := waveLevel(tt.wave)
+		if level.Number != tt.wave || level.EnemiesToBoss != tt.enemies || !reflect.DeepEqual(level.Bosses, tt.bosses) {
+			t.Errorf("waveLevel(%d) = %+v, want 敵 %d・ボス %v", tt.wave, level, tt.enemies, tt.bosses)
+		}
+	}
+}
+
+func TestWaveAdvances(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeEndless, defaultRules)
+	testPlayer(gameRoom, "p1", 400, 500)
+
+	gameRoom.EnemiesDefeated = waveBaseEnemies - 1
+	waveSystem(gameRoom)
+	if gameRoom.Level != 1 {
+		t.Fatalf("規定数の前にウェーブ %d へ進んだ", gameRoom.Level)
+	}
+	gameRoom.EnemiesDefeated++
+	waveSystem(gameRoom)
+	if gameRoom.Level != 2 || gameRoom.EnemiesDefeated != 0 || gameRoom.GameState != "playing" {
+		t.Fatalf("規定数を倒しても進まない: ウェーブ %d・撃破数 %d・%s", gameRoom.Level, gameRoom.EnemiesDefeated, gameRoom.GameState)
+	}
+
+	// ボスのウェーブは撃破数では進まない
+	gameRoom.Level = bossEveryWaves * len(levels)
+	gameRoom.EnemiesDefeated = 1000
+	waveSystem(gameRoom)
+	if gameRoom.Level != bossEveryWaves*len(levels) {
+		t.Fatalf("ボスのウェーブが撃破数で進んだ: ウェーブ %d", gameRoom.Level)
+	}
+
+	// 最終レベルのボスを倒してもクリアにならず、次のウェーブへ
+	spawnBosses(gameRoom)
+	shot := &Entity{Collider: &Collider{Owner: "p1"}}
+	for _, boss := range gameRoom.Bosses {
+		defeatBoss(gameRoom, boss, shot)
+	}
+	if gameRoom.GameState != "playing" || gameRoom.Level != bossEveryWaves*len(levels)+1 {
+		t.Fatalf("ボスを倒した後: %s・ウェーブ %d", gameRoom.GameState, gameRoom.Level)
+	}
+}
+
+func TestWaveSystemIgnoresOtherModes(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	gameRoom.EnemiesDefeated = 1000
+	waveSystem(gameRoom)
+	if gameRoom.Level != 1 {
+		t.Fatalf("協力プレイでウェーブが進んだ: レベル %d", gameRoom.Level)
+	}
+	if got := waveScale(gameRoom, speedPerWave, maxWaveSpeed); got != 1 {
+		t.Errorf("協力プレイの倍率 %v, want 1", got)
+	}
+}
+
+func TestWaveScale(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeEndless, defaultRules)
+	tests := []struct {
+		wave int
+		want float64
+	}{
+		{1, 1}, {3, 1.1}, {21, maxWaveSpeed}, {100, maxWaveSpeed},
+	}
+	for _, tt := range tests {
+		gameRoom.Level = tt.wave
+		if got := waveScale(gameRoom, speedPerWave, maxWaveSpeed); got != tt.want {
+			t.Errorf("ウェーブ %d の速度倍率 %v, want %v", tt.wave, got, tt.want)
+		}
+	}
+	// 上限のない倍率（ボス体力）は上がり続ける
+	gameRoom.Level = 101
+	if got := waveScale(gameRoom, bossHealthPerWave, 0); got != 11 {
+		t.Errorf("ウェーブ 101 のボス体力倍率 %v, want 11", got)
+	}
+}
+
+func TestWaveShiftsArchetypes(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeEndless, defaultRules)
+	share := func(wave int) float64 {
+		gameRoom.Level = wave
+		gameRoom.rng = rand.New(rand.NewSource(1))
+		grunts := 0
+		for i := 0; i < 2000; i++ {
+			if pickEnemyArchetype(gameRoom).Name == "grunt" {
+				grunts++
+			}
+		}
+		return float64(grunts) / 2000
+	}
+	early, late := share(1), share(20)
+	if late >= early-0.2 {
+		t.Errorf("ウェーブ20でも雑魚の割合が減らない: %.2f → %.2f", early, late)
+	}
+}
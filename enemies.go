@@ -0,0 +1,158 @@
+/**
+ * @file enemies.go
+ * @description 雑魚敵の種類（アーキタイプ）定義と生成
+ *
+ * 概要:
+ * - 敵アーキタイプごとのサイズ・速度・体力・ドロップテーブル
+ * - 出現比率に従ったランダム生成（エンドレスモードではウェーブが進むほど比率が変わる）
+ */
+
+package main
+
+/**
+ * 敵アーキタイプ構造体
+ * @property {string} Name - アーキタイプ名（Entity.Kind に設定される）
+ * @property {int} Weight - 出現比率の重み
+ * @property {int} Size - 幅・高さ（ピクセル）
+ * @property {int} MinSpeed - 最小落下速度
+ * @property {int} MaxSpeed - 最大落下速度
+ * @property {int} Health - 体力
+ * @property {string} DropTable - 撃破時に使用するドロップテーブル名
+ * @property {int} WaveWeight - エンドレスモードで1ウェーブごとに増える出現比率の重み
+ */
+type EnemyArchetype struct {
+	Name       string
+	Weight     int
+	Size       int
+	MinSpeed   int
+	MaxSpeed   int
+	Health     int
+	DropTable  string
+	WaveWeight int
+}
+
+// 敵アーキタイプ一覧
+var enemyArchetypes = []EnemyArchetype{
+	{Name: "grunt", Weight: 60, Size: 30, MinSpeed: 1, MaxSpeed: 2, Health: 1, DropTable: "grunt"},
+	{Name: "scout", Weight: 20, Size: 20, MinSpeed: 3, MaxSpeed: 4, Health: 1, DropTable: "scout", WaveWeight: 4},
+	{Name: "tank", Weight: 10, Size: 36, MinSpeed: 1, MaxSpeed: 1, Health: 8, DropTable: "tank", WaveWeight: 3},
+	{Name: "carrier", Weight: 10, Size: 40, MinSpeed: 1, MaxSpeed: 1, Health: 4, DropTable: "carrier", WaveWeight: 2},
+}
+
+/**
+ * 出現比率に従って敵アーキタイプを選ぶ
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {EnemyArchetype} - 選ばれたアーキタイプ
+ */
+func pickEnemyArchetype(gameRoom *GameRoom) EnemyArchetype {
+	extra := max(endlessWave(gameRoom)-1, 0)
+	weight := func(a EnemyArchetype) int {
+		return a.Weight + a.WaveWeight*extra
+	}
+	total := 0
+	for _, a := range enemyArchetypes {
+		total += weight(a)
+	}
+	r := gameRoom.rng.Intn(total)
+	for _, a := range enemyArchetypes {
+		if r < weight(a) {
+			return a
+		}
+		r -= weight(a)
+	}
+	return enemyArchetypes[0]
+}
+
+/**
+ * 敵の作成
+ * ランダムなアーキタイプ・位置・速度で敵を生成する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ */
+func createEnemy(gameRoom *GameRoom) {
+	// ゲームがプレイ中でボスが出現していない場合のみ敵を生成
+	if gameRoom.GameState != "playing" || gameRoom.BossSpawned {
+		return
+	}
+
+	gameRoom.Mutex.Lock()
+	defer gameRoom.Mutex.Unlock()
+
+	archetype := pickEnemyArchetype(gameRoom)
+
+	x := float64(gameRoom.rng.Intn(600))
+	if roomMode(gameRoom).Teams {
+		x = randomSideX(gameRoom.rng.Intn(2), archetype.Size) // 両陣地に同じ割合で出現
+	}
+	spawnEnemy(gameRoom, archetype, x)
+}
+
+/**
+ * 画面上端に敵を追加する（エンドレスモードではウェーブに応じて速くなる）
+ * 種類・位置・速度はルームの乱数で決める（デイリーチャレンジでは全ルームで同じ並びになる）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {EnemyArchetype} archetype - 敵のアーキタイプ
+ * @param {float64} x - 出現位置のX座標
+ * @returns {*Entity} - 追加された敵
+ */
+func spawnEnemy(gameRoom *GameRoom, archetype EnemyArchetype, x float64) *Entity {
+	enemy, c := gameRoom.entities.Spawn()
+	enemy.Type = "enemy"
+	enemy.Kind = archetype.Name
+	enemy.Transform = transformAt(x, 0, archetype.Size, archetype.Size)
+	enemy.Velocity = c.Velocity(Velocity{
+		VelocityX: float64(gameRoom.rng.Intn(3) - 1),
+		VelocityY: float64(archetype.MinSpeed + gameRoom.rng.Intn(archetype.MaxSpeed-archetype.MinSpeed+1)),
+		Scale:     waveScale(gameRoom, speedPerWave, maxWaveSpeed),
+	})
+	enemy.Collider = c.Collider(Collider{
+		// 見た目に合わせた三角形の衝突形状（アーキタイプごとに共有）
+		Shape:  enemyShape(archetype),
+		Damage: 10, // 接触ダメージ
+	})
+	enemy.Health = c.Health(Health{Current: archetype.Health, Max: archetype.Health})
+	enemy.AI = c.AI(AI{Update: enemyAI})
+	enemy.Lifetime = c.Lifetime(Lifetime{})
+	setLayer(enemy, layerEnemy)
+	gameRoom.Enemies[enemy.ID] = enemy
+	return enemy
+}
+
+// アーキタイプごとの衝突形状（キー：アーキタイプ名、起動時に作成し以後は読み取りのみ）
+var enemyShapes = buildEnemyShapes()
+
+/**
+ * 全アーキタイプの衝突形状を作成する（見た目に合わせた三角形）
+ * 複数のルームのゲームループから同時に読まれるため、実行中には書き込まない
+ * @returns {map[string]*Shape} - 衝突形状（キー：アーキタイプ名）
+ */
+func buildEnemyShapes() map[string]*Shape {
+	shapes := make(map[string]*Shape, len(enemyArchetypes))
+	for _, a := range enemyArchetypes {
+		size := float64(a.Size)
+		shapes[a.Name] = polygonShape(Vec2{size / 2, 0}, Vec2{size, size}, Vec2{0, size})
+	}
+	return shapes
+}
+
+/**
+ * アーキタイプの衝突形状を取得する
+ * @param {EnemyArchetype} archetype - アーキタイプ
+ * @returns {*Shape} - 衝突形状
+ */
+func enemyShape(archetype EnemyArchetype) *Shape {
+	return enemyShapes[archetype.Name]
+}
+
+/**
+ * 敵アーキタイプを名前で取得する
+ * @param {string} name - アーキタイプ名
+ * @returns {EnemyArchetype} - アーキタイプ（見つからなければ先頭）
+ */
+func enemyArchetype(name string) EnemyArchetype {
+	for _, a := range enemyArchetypes {
+		if a.Name == name {
+			return a
+		}
+	}
+	return enemyArchetypes[0]
+}
@@ -0,0 +1,292 @@
+/**
+ * @file items.go
+ * @description アイテム（パワーアップ）の種類・ドロップテーブル・効果
+ *
+ * 概要:
+ * - アイテム種類: 武器強化・回復・シールド・スピード・ボム・残機・スコア倍率・武器切り替え
+ * - 敵アーキタイプごとのドロップ率テーブル
+ * - プレイヤーに付与される時間制限付き効果の管理
+ */
+
+package main
+
+import "math/rand"
+
+// アイテム種類（Pickup.Item に設定される）
+const (
+	itemWeapon     = "weapon"     // 武器強化（FirePower +1、上限あり）
+	itemHeal       = "heal"       // 体力回復
+	itemShield     = "shield"     // 一定時間ダメージ無効
+	itemSpeed      = "speed"      // 一定時間移動速度アップ
+	itemBomb       = "bomb"       // 画面上の敵と敵弾を一掃
+	itemLife       = "life"       // 残機 +1
+	itemMultiplier = "multiplier" // 一定時間スコア倍率アップ
+)
+
+const (
+	// FirePower の上限
+	maxFirePower = 5
+	// 体力の上限
+	maxPlayerHealth = 100
+	// 回復アイテムの回復量
+	healAmount = 30
+	// 残機の上限
+	maxLives = 5
+	// スピードアップ中の移動速度倍率
+	speedBoostMultiplier = 1.5
+	// スコア倍率アイテムの倍率
+	scoreMultiplierBonus = 2
+)
+
+// 時間制限付き効果の持続ティック数（60ティック = 1秒）
+var effectDurations = map[string]int{
+	itemShield:     60 * 5,
+	itemSpeed:      60 * 8,
+	itemMultiplier: 60 * 10,
+}
+
+/**
+ * ドロップ候補構造体
+ * @property {string} Item - アイテム種類
+ * @property {int} Weight - 抽選の重み
+ */
+type DropEntry struct {
+	Item   string
+	Weight int
+}
+
+/**
+ * ドロップテーブル構造体
+ * @property {int} Chance - 何かを落とす確率（パーセント）
+ * @property {[]DropEntry} Entries - 落とすアイテムの候補
+ */
+type DropTable struct {
+	Chance  int
+	Entries []DropEntry
+}
+
+// ドロップテーブル（キー：テーブル名、敵アーキタイプやボスから参照される）
+var dropTables = map[string]DropTable{
+	"grunt": {
+		Chance: 30,
+		Entries: []DropEntry{
+			{Item: itemWeapon, Weight: 40},
+			{Item: itemHeal, Weight: 35},
+			{Item: itemSpeed, Weight: 15},
+			{Item: itemMultiplier, Weight: 10},
+			{Item: itemWeaponSpread, Weight: 5},
+			{Item: itemWeaponLaser, Weight: 5},
+		},
+	},
+	"scout": {
+		Chance: 50,
+		Entries: []DropEntry{
+			{Item: itemWeapon, Weight: 30},
+			{Item: itemSpeed, Weight: 30},
+			{Item: itemShield, Weight: 20},
+			{Item: itemMultiplier, Weight: 20},
+			{Item: itemWeaponHoming, Weight: 10},
+			{Item: itemWeaponCharge, Weight: 10},
+		},
+	},
+	"carrier": {
+		Chance: 100,
+		Entries: []DropEntry{
+			{Item: itemWeapon, Weight: 30},
+			{Item: itemShield, Weight: 25},
+			{Item: itemBomb, Weight: 25},
+			{Item: itemLife, Weight: 20},
+			{Item: itemWeaponRail, Weight: 15},
+			{Item: itemWeaponLaser, Weight: 10},
+		},
+	},
+	"tank": {
+		Chance: 60,
+		Entries: []DropEntry{
+			{Item: itemWeapon, Weight: 40},
+			{Item: itemHeal, Weight: 30},
+			{Item: itemWeaponRail, Weight: 30},
+		},
+	},
+	"boss": {
+		Chance: 100,
+		Entries: []DropEntry{
+			{Item: itemLife, Weight: 50},
+			{Item: itemBomb, Weight: 50},
+		},
+	},
+}
+
+/**
+ * ドロップテーブルを抽選する
+ * @param {*rand.Rand} rng - 乱数（ルームの攻撃・ドロップ用）
+ * @param {string} table - ドロップテーブル名
+ * @returns {string} - 落とすアイテム種類（何も落とさない場合は空文字）
+ */
+func rollDrop(rng *rand.Rand, table string) string {
+	t, ok := dropTables[table]
+	if !ok || rng.Intn(100) >= t.Chance {
+		return ""
+	}
+	total := 0
+	for _, e := range t.Entries {
+		total += e.Weight
+	}
+	if total == 0 {
+		return ""
+	}
+	r := rng.Intn(total)
+	for _, e := range t.Entries {
+		if r < e.Weight {
+			return e.Item
+		}
+		r -= e.Weight
+	}
+	return ""
+}
+
+/**
+ * アイテムをドロップする
+ * ドロップテーブルを抽選し、当選したら指定位置にアイテムを生成する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {string} table - ドロップテーブル名
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ */
+func dropItem(gameRoom *GameRoom, table string, x, y float64) {
+	kind := rollDrop(gameRoom.combatRng, table)
+	if kind == "" {
+		return
+	}
+	item, c := gameRoom.entities.Spawn()
+	item.Type = "item"
+	item.Transform = transformAt(x, y, 15, 15)
+	item.Velocity = c.Velocity(Velocity{VelocityX: 0, VelocityY: 1})
+	item.Collider = c.Collider(Collider{})
+	item.Lifetime = c.Lifetime(Lifetime{})
+	item.Pickup = c.Pickup(Pickup{Item: kind})
+	setLayer(item, layerPickup)
+	gameRoom.Items[item.ID] = item
+}
+
+/**
+ * アイテム効果を適用する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - アイテムを取得したプレイヤー
+ * @param {string} kind - アイテム種類
+ */
+func applyItem(gameRoom *GameRoom, p *Player, kind string) {
+	switch kind {
+	case itemWeapon:
+		if p.FirePower < maxFirePower {
+			p.FirePower++
+		}
+	case itemHeal:
+		p.Health.Current += healAmount
+		if p.Health.Current > p.Health.Max {
+			p.Health.Current = p.Health.Max
+		}
+	case itemLife:
+		if lives := livesOf(gameRoom, p); *lives < livesLimit(gameRoom) {
+			*lives++
+		}
+	case itemBomb:
+		detonateBomb(gameRoom, p)
+	default:
+		// 武器切り替え
+		if weapon, ok := weaponPickups[kind]; ok {
+			p.Weapon.Name = weapon
+			return
+		}
+		// 時間制限付き効果（再取得で持続時間をリセット）
+		if duration, ok := effectDurations[kind]; ok {
+			p.Effects[kind] = duration
+		}
+	}
+}
+
+/**
+ * ボムを起爆する
+ * 画面上の全ての雑魚敵と敵弾を消去し、撃破数とスコアに加算する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - ボムを使用したプレイヤー
+ */
+func detonateBomb(gameRoom *GameRoom, p *Player) {
+	for _, e := range gameRoom.Enemies {
+		despawn(gameRoom, e)
+		gameRoom.EnemiesDefeated++
+		awardScore(gameRoom, p, scorePoints[scoreBomb], scoreBomb)
+	}
+	for _, b := range gameRoom.Bullets {
+		if b.Layer == layerEnemyShot {
+			despawn(gameRoom, b)
+		}
+	}
+}
+
+/**
+ * 効果が有効かどうか
+ * @param {*Player} p - プレイヤー
+ * @param {string} kind - 効果の種類
+ * @returns {bool} - 有効ならtrue
+ */
+func hasEffect(p *Player, kind string) bool {
+	return p.Effects[kind] > 0
+}
+
+/**
+ * 時間制限付き効果の残り時間を1ティック進める
+ * @param {*Player} p - プレイヤー
+ */
+func tickEffects(p *Player) {
+	for kind, remaining := range p.Effects {
+		if remaining <= 1 {
+			delete(p.Effects, kind)
+			continue
+		}
+		p.Effects[kind] = remaining - 1
+	}
+}
+
+/**
+ * プレイヤーにダメージを与える
+ * ダメージ量は発生元の Damage、無敵時間・クールダウン・ノックバックは発生元のレイヤーの規則に従う（damage.go）。
+ * シールド中・無敵時間中・発生元のクールダウン中は無効化し、体力が0になったら倒れた状態にする（respawn.go）。
+ * 全員が倒れてリスポーン待ちもいなければゲームオーバーにする
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - ダメージを受けるプレイヤー
+ * @param {*Entity} source - ダメージの発生元（敵弾・敵・ボス）
+ * @returns {bool} - ダメージを与えた場合true（無効化された場合false）
+ */
+func damagePlayer(gameRoom *GameRoom, p *Player, source *Entity) bool {
+	if !isAlive(p) {
+		return false
+	}
+	rule := damageRules[source.Layer]
+	if rule.Knockback > 0 {
+		applyKnockback(&p.Entity, source, rule.Knockback)
+	}
+	if p.Invulnerable > 0 || hasEffect(p, itemShield) || onCooldown(p.Health, source.ID) {
+		return false
+	}
+	if rule.Cooldown > 0 {
+		p.cooldowns = append(p.cooldowns, hitCooldown{Source: source.ID, Ticks: rule.Cooldown})
+	}
+	p.Invulnerable = rule.IFrames
+
+	damage := source.Damage
+	if rule.Scale > 0 {
+		damage *= rule.Scale
+	}
+	p.Health.Current -= damage
+	p.Stage.Damaged = true
+	if p.Health.Current > 0 {
+		return true
+	}
+	killPlayer(gameRoom, p)
+	if roomMode(gameRoom).PvP {
+		recordFrag(gameRoom, p, source.Owner)
+	}
+	checkGameOver(gameRoom)
+	return true
+}
@@ -0,0 +1,136 @@
+/**
+ * @file items_test.go
+ * @description アイテムのドロップテーブルと効果のテスト
+ *
+ * 概要:
+ * - 全アーキタイプのドロップテーブルが存在し、既知のアイテムだけを落とすことを確認する
+ * - ドロップ率100%のテーブルは必ず、未知のテーブルは何も落とさないことを確認する
+ * - 武器強化・回復・残機が上限を超えないことを確認する
+ * - 時間制限付き効果が再取得で延長され、時間切れで消えることを確認する
+ * - ボムが雑魚敵と敵弾だけを消去することを確認する
+ */
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDropTables(t *testing.T) {
+	known := map[string]bool{
+		itemWeapon: true, itemHeal: true, itemShield: true, itemSpeed: true,
+		itemBomb: true, itemLife: true, itemMultiplier: true,
+	}
+	for item := range weaponPickups {
+		known[item] = true
+	}
+	for _, a := range enemyArchetypes {
+		if _, ok := dropTables[a.DropTable]; !ok {
+			t.Errorf("%s のドロップテーブル %q がない", a.Name, a.DropTable)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for name, table := range dropTables {
+		drops := 0
+		for i := 0; i < 1000; i++ {
+			item := rollDrop(rng, name)
+			if item == "" {
+				continue
+			}
+			drops++
+			if !known[item] {
+				t.Fatalf("%s が未知のアイテム %q を落とした", name, item)
+			}
+		}
+		if table.Chance == 100 && drops != 1000 {
+			t.Errorf("%s: ドロップ率100%%なのに %d/1000 回しか落とさない", name, drops)
+		}
+		if drops == 0 {
+			t.Errorf("%s: 1000回で一度も落とさない", name)
+		}
+	}
+	if item := rollDrop(rng, "missing"); item != "" {
+		t.Errorf("未知のテーブルから %q を落とした", item)
+	}
+}
+
+func TestApplyItemCaps(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 100)
+
+	for i := 0; i < maxFirePower+2; i++ {
+		applyItem(gameRoom, p, itemWeapon)
+	}
+	if p.FirePower != maxFirePower {
+		t.Errorf("武器レベル %d, want %d", p.FirePower, maxFirePower)
+	}
+
+	p.Health.Current = maxPlayerHealth - healAmount - 5
+	applyItem(gameRoom, p, itemHeal)
+	if p.Health.Current != maxPlayerHealth-5 {
+		t.Errorf("回復後の体力 %d, want %d", p.Health.Current, maxPlayerHealth-5)
+	}
+	applyItem(gameRoom, p, itemHeal)
+	if p.Health.Current != maxPlayerHealth {
+		t.Errorf("体力が上限を超えた: %d", p.Health.Current)
+	}
+
+	for i := 0; i < maxLives+2; i++ {
+		applyItem(gameRoom, p, itemLife)
+	}
+	if p.Lives != maxLives {
+		t.Errorf("残機 %d, want %d", p.Lives, maxLives)
+	}
+}
+
+func TestTimedEffects(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 100)
+
+	applyItem(gameRoom, p, itemShield)
+	for i := 0; i < 60; i++ {
+		tickEffects(p)
+	}
+	// 再取得で持続時間が最初からになる
+	applyItem(gameRoom, p, itemShield)
+	if got := p.Effects[itemShield]; got != effectDurations[itemShield] {
+		t.Fatalf("再取得後の残り %d, want %d", got, effectDurations[itemShield])
+	}
+	for i := 0; i < effectDurations[itemShield]-1; i++ {
+		tickEffects(p)
+	}
+	if !hasEffect(p, itemShield) {
+		t.Fatal("持続時間より早くシールドが切れた")
+	}
+	tickEffects(p)
+	if hasEffect(p, itemShield) {
+		t.Fatal("持続時間が過ぎてもシールドが残っている")
+	}
+	if _, ok := p.Effects[itemShield]; ok {
+		t.Error("切れた効果がマップに残っている")
+	}
+}
+
+func TestBombClearsEnemiesAndEnemyShots(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	for i := 0; i < 3; i++ {
+		createEnemy(gameRoom)
+	}
+	spawnBossBullet(gameRoom, &Entity{Transform: transformAt(100, 100, 10, 10)}, 0, 3)
+	own, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, -10, 4, 10, 1, 0)
+
+	applyItem(gameRoom, p, itemBomb)
+
+	if len(gameRoom.Enemies) != 0 || gameRoom.EnemiesDefeated != 3 {
+		t.Errorf("敵が残っている: %d 体（撃破数 %d）", len(gameRoom.Enemies), gameRoom.EnemiesDefeated)
+	}
+	if len(gameRoom.Bullets) != 1 || gameRoom.Bullets[own.ID] != own {
+		t.Errorf("ボム後の弾 %d 発（自分の弾は残るはず）", len(gameRoom.Bullets))
+	}
+	if p.Score != 3*scorePoints[scoreBomb] {
+		t.Errorf("ボムの得点 %d, want %d", p.Score, 3*scorePoints[scoreBomb])
+	}
+}
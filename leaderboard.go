@@ -0,0 +1,408 @@
+/**
+ * @file leaderboard.go
+ * @description 永続的なハイスコア（リーダーボード）
+ *
+ * 概要:
+ * - ゲームオーバー・クリア時に、ルームの各プレイヤーのスコアを自動で登録する
+ * - 保存先は LeaderboardStore インターフェースで差し替えられる（標準はJSONファイル）
+ * - GET /api/leaderboard でモード・難易度・期間ごとの上位スコアを返す
+ * - デイリーチャレンジは日付ごとにクリアタイムの速い順で返す
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// リーダーボードの保存先ファイル（環境変数 LEADERBOARD_FILE で変更、空なら保存しない）
+	defaultLeaderboardFile = "data/leaderboard.json"
+	// 区分（モード・難易度・デイリーチャレンジの日付）ごとに保存する記録数の上限（超えたら順位の低い記録から削除）
+	maxLeaderboardEntries = 500
+	// 1回の取得件数の既定値と上限
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// 集計期間（キー：期間名、値：現在から遡る長さ。0なら全期間）
+var leaderboardPeriods = map[string]time.Duration{
+	"all":   0,
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+/**
+ * リーダーボードの記録構造体
+ * @property {string} PlayerID - プレイヤーID
+ * @property {string} Name - プレイヤー名
+ * @property {string} Account - ログイン中だったプレイヤーのユーザー名（ゲストは空）
+ * @property {int} Score - 最終スコア（エンドレスモードでは到達したウェーブ）
+ * @property {string} Mode - ゲームモード
+ * @property {string} Difficulty - 難易度
+ * @property {int} Level - 到達したレベル（エンドレスモードではウェーブ）
+ * @property {bool} Cleared - クリアしたか（falseならゲームオーバー）
+ * @property {int} Seconds - ゲーム開始からの経過秒数
+ * @property {string} Day - デイリーチャレンジの日付（他のモードは空）
+ * @property {int} ClearTime - デイリーチャレンジのクリアタイム（ミリ秒）
+ * @property {time.Time} At - 記録した日時
+ */
+type LeaderboardEntry struct {
+	PlayerID   string    `json:"playerId"`
+	Name       string    `json:"name"`
+	Account    string    `json:"account,omitempty"`
+	Score      int       `json:"score"`
+	Mode       string    `json:"mode"`
+	Difficulty string    `json:"difficulty"`
+	Level      int       `json:"level"`
+	Cleared    bool      `json:"cleared"`
+	Seconds    int       `json:"seconds"`
+	Day        string    `json:"day,omitempty"`
+	ClearTime  int       `json:"clearTime,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+/**
+ * リーダーボードの検索条件構造体
+ * @property {string} Mode - ゲームモード
+ * @property {string} Difficulty - 難易度
+ * @property {time.Time} Since - この日時以降の記録のみ（ゼロ値なら全期間）
+ * @property {string} Day - この日付の記録のみ（空なら日付で絞り込まない）
+ * @property {bool} Fastest - trueならクリアした記録のみをクリアタイムの速い順に返す
+ * @property {int} Limit - 取得件数
+ */
+type LeaderboardQuery struct {
+	Mode       string
+	Difficulty string
+	Since      time.Time
+	Day        string
+	Fastest    bool
+	Limit      int
+}
+
+/**
+ * リーダーボードの保存先インターフェース
+ * 実装は複数のゴルーチンから同時に呼ばれても安全であること
+ */
+type LeaderboardStore interface {
+	// 記録を追加する
+	Submit(entries []LeaderboardEntry) error
+	// 条件に合う記録をスコアの高い順（Fastest ならクリアタイムの速い順）に返す
+	Top(query LeaderboardQuery) ([]LeaderboardEntry, error)
+}
+
+// 使用中のリーダーボード（main で初期化する）
+var leaderboard LeaderboardStore = newFileLeaderboard("")
+
+/**
+ * JSONファイルに保存するリーダーボード
+ * 記録はメモリに持ち、追加のたびにファイル全体を書き直す（ローカル・小規模向け）
+ * @property {string} path - 保存先ファイル（空なら保存しない）
+ * @property {sync.Mutex} mutex - 同時アクセス防止のミューテックス
+ * @property {[]LeaderboardEntry} entries - 記録（古い順）
+ */
+type fileLeaderboard struct {
+	path    string
+	mutex   sync.Mutex
+	entries []LeaderboardEntry
+}
+
+/**
+ * JSONファイルのリーダーボードを作成する
+ * @param {string} path - 保存先ファイル（空ならメモリのみ）
+ * @returns {*fileLeaderboard} - 作成されたリーダーボード
+ */
+func newFileLeaderboard(path string) *fileLeaderboard {
+	return &fileLeaderboard{path: path}
+}
+
+/**
+ * JSONファイルのリーダーボードを開く
+ * ファイルがなければ空のリーダーボードとして始める
+ * @param {string} path - 保存先ファイル
+ * @returns {*fileLeaderboard, error} - リーダーボードと読み込みエラー
+ */
+func openFileLeaderboard(path string) (*fileLeaderboard, error) {
+	store := newFileLeaderboard(path)
+	if path == "" {
+		return store, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+/**
+ * 記録の区分
+ * @property {string} Mode - ゲームモード
+ * @property {string} Difficulty - 難易度
+ * @property {string} Day - デイリーチャレンジの日付（他のモードは空）
+ */
+type leaderboardBucket struct {
+	Mode       string
+	Difficulty string
+	Day        string
+}
+
+/**
+ * 記録の区分を取得する
+ * @returns {leaderboardBucket} - 区分
+ */
+func (e LeaderboardEntry) bucket() leaderboardBucket {
+	return leaderboardBucket{Mode: e.Mode, Difficulty: e.Difficulty, Day: e.Day}
+}
+
+/**
+ * 記録aが記録bより上位か
+ * @param {LeaderboardEntry} a - 記録
+ * @param {LeaderboardEntry} b - 記録
+ * @param {bool} fastest - trueならクリアタイムの速い順（デイリーチャレンジ）、falseならスコアの高い順
+ * @returns {bool} - aが上位ならtrue（同点なら順位は変わらない）
+ */
+func ranksAbove(a, b LeaderboardEntry, fastest bool) bool {
+	if fastest {
+		return a.ClearTime < b.ClearTime
+	}
+	return a.Score > b.Score
+}
+
+/**
+ * 記録を追加してファイルに保存する
+ * 追加した区分の記録が上限を超えたら、順位の低い記録から削除する
+ * @param {[]LeaderboardEntry} entries - 追加する記録
+ * @returns {error} - 保存エラー（あれば）
+ */
+func (s *fileLeaderboard) Submit(entries []LeaderboardEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = append(s.entries, entries...)
+	for _, e := range entries {
+		s.prune(e.bucket())
+	}
+	return s.save()
+}
+
+/**
+ * 区分の記録を上限まで減らす（順位の低い記録から削除し、残りは記録した順のまま）
+ * @param {leaderboardBucket} bucket - 区分
+ */
+func (s *fileLeaderboard) prune(bucket leaderboardBucket) {
+	var ranked []int
+	for i, e := range s.entries {
+		if e.bucket() == bucket {
+			ranked = append(ranked, i)
+		}
+	}
+	if len(ranked) <= maxLeaderboardEntries {
+		return
+	}
+	// 同点なら先に記録した方を残す
+	fastest := gameModes[bucket.Mode].Daily
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranksAbove(s.entries[ranked[i]], s.entries[ranked[j]], fastest)
+	})
+	drop := make(map[int]bool, len(ranked)-maxLeaderboardEntries)
+	for _, i := range ranked[maxLeaderboardEntries:] {
+		drop[i] = true
+	}
+	kept := s.entries[:0]
+	for i, e := range s.entries {
+		if !drop[i] {
+			kept = append(kept, e)
+		}
+	}
+	s.entries = kept
+}
+
+/**
+ * 条件に合う記録をスコアの高い順（Fastest ならクリアタイムの速い順）に返す
+ * @param {LeaderboardQuery} query - 検索条件
+ * @returns {[]LeaderboardEntry, error} - 記録
+ */
+func (s *fileLeaderboard) Top(query LeaderboardQuery) ([]LeaderboardEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	top := []LeaderboardEntry{}
+	for _, e := range s.entries {
+		if e.Mode != query.Mode || e.Difficulty != query.Difficulty || e.At.Before(query.Since) {
+			continue
+		}
+		if query.Day != "" && e.Day != query.Day || query.Fastest && !e.Cleared {
+			continue
+		}
+		top = append(top, e)
+	}
+	// 同点なら先に記録した方を上位にする
+	sort.SliceStable(top, func(i, j int) bool {
+		return ranksAbove(top[i], top[j], query.Fastest)
+	})
+	if len(top) > query.Limit {
+		top = top[:query.Limit]
+	}
+	return top, nil
+}
+
+/**
+ * 記録をファイルに書き出す（一時ファイルに書いてから置き換える）
+ * @returns {error} - 書き込みエラー（あれば）
+ */
+func (s *fileLeaderboard) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+/**
+ * ルームのプレイヤーのスコアをリーダーボードに登録する
+ * ゲームオーバー・クリアになったときに呼ぶ。ファイルへの書き込みはロックの外で行う
+ * デイリーチャレンジはクリアしたときのみ、クリアタイムを登録する。既定と異なるルールのルームは登録しない
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func submitScores(gameRoom *GameRoom) {
+	now := time.Now()
+	mode := roomMode(gameRoom)
+	cleared := gameRoom.GameState == "clear"
+	if mode.Daily && !cleared || gameRoom.Rules != defaultRules {
+		return
+	}
+	var entries []LeaderboardEntry
+	for _, p := range gameRoom.Players {
+		score := p.Score
+		if mode.Endless {
+			score = gameRoom.Level // 到達したウェーブで競う
+		}
+		if score <= 0 && !mode.Daily {
+			continue
+		}
+		entries = append(entries, LeaderboardEntry{
+			PlayerID:   p.ID,
+			Name:       p.Name,
+			Account:    p.account,
+			Score:      score,
+			Mode:       gameRoom.Mode,
+			Difficulty: gameRoom.Difficulty,
+			Level:      gameRoom.Level,
+			Cleared:    cleared,
+			Seconds:    gameRoom.runTicks / 60,
+			Day:        gameRoom.Day,
+			At:         now,
+		})
+		if mode.Daily {
+			entries[len(entries)-1].ClearTime = runMillis(gameRoom)
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	go func() {
+		if err := leaderboard.Submit(entries); err != nil {
+			log.Println("リーダーボードの保存エラー:", err)
+		}
+	}()
+}
+
+/**
+ * リーダーボード取得ハンドラー
+ * GET /api/leaderboard?mode=coop&difficulty=normal&period=week&limit=10
+ * GET /api/leaderboard?mode=daily&day=2024-01-01（dayを省略すると今日）
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleLeaderboard(c echo.Context) error {
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = defaultMode
+	}
+	if _, ok := gameModes[mode]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "不明なモードです")
+	}
+
+	difficulty := c.QueryParam("difficulty")
+	if difficulty == "" {
+		difficulty = defaultDifficulty
+	}
+	if _, ok := difficulties[difficulty]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "不明な難易度です")
+	}
+
+	period := c.QueryParam("period")
+	if period == "" {
+		period = "all"
+	}
+	span, ok := leaderboardPeriods[period]
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "不明な期間です")
+	}
+	var since time.Time
+	if span > 0 {
+		since = time.Now().Add(-span)
+	}
+
+	limit := defaultLeaderboardLimit
+	if s := c.QueryParam("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxLeaderboardLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "件数は1〜100で指定してください")
+		}
+		limit = n
+	}
+
+	query := LeaderboardQuery{Mode: mode, Difficulty: difficulty, Since: since, Limit: limit}
+	if gameModes[mode].Daily {
+		query.Day = c.QueryParam("day")
+		if query.Day == "" {
+			query.Day = challengeDay(time.Now())
+		}
+		if _, err := time.Parse("2006-01-02", query.Day); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "日付はYYYY-MM-DDで指定してください")
+		}
+		query.Fastest = true
+	}
+
+	top, err := leaderboard.Top(query)
+	if err != nil {
+		log.Println("リーダーボードの取得エラー:", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"mode":       mode,
+		"difficulty": difficulty,
+		"period":     period,
+		"day":        query.Day,
+		"entries":    top,
+	})
+}
@@ -0,0 +1,214 @@
+/**
+ * @file leaderboard_test.go
+ * @description リーダーボードのテスト
+ *
+ * 概要:
+ * - 上限を超えたときに、古い記録ではなく区分ごとに順位の低い記録から削除されることを確認する
+ * - 終了したルームから、得点したプレイヤーの記録だけが登録されることを確認する
+ * - 既定と異なるルールのルーム・未クリアのデイリーチャレンジは登録されないことを確認する
+ * - 取得APIが不正な条件を拒否し、条件に合う記録を返すことを確認する
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+/**
+ * 登録された記録をチャネルに送るリーダーボード（登録は別のゴルーチンで行われるため）
+ * @property {chan []LeaderboardEntry} submitted - 登録された記録
+ */
+type recordingLeaderboard struct {
+	submitted chan []LeaderboardEntry
+}
+
+func (r *recordingLeaderboard) Submit(entries []LeaderboardEntry) error {
+	r.submitted <- entries
+	return nil
+}
+
+func (r *recordingLeaderboard) Top(query LeaderboardQuery) ([]LeaderboardEntry, error) {
+	return nil, nil
+}
+
+/**
+ * ルームのスコアを登録し、そのルームのプレイヤーの記録を受け取る
+ * 他のテストで終了したルームの登録が遅れて届くことがあるため、プレイヤーIDで絞り込む
+ * @param {*testing.T} t - テスト
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @returns {[]LeaderboardEntry} - 登録された記録（登録されなければnil）
+ */
+func submitAndWait(t *testing.T, gameRoom *GameRoom) []LeaderboardEntry {
+	t.Helper()
+	store := &recordingLeaderboard{submitted: make(chan []LeaderboardEntry, 8)}
+	saved := leaderboard
+	leaderboard = store
+	defer func() { leaderboard = saved }()
+
+	submitScores(gameRoom)
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case entries := <-store.submitted:
+			if len(entries) > 0 && gameRoom.Players[entries[0].PlayerID] != nil {
+				return entries
+			}
+		case <-timeout:
+			return nil
+		}
+	}
+}
+
+func TestLeaderboardPruneKeepsHighScores(t *testing.T) {
+	store := newFileLeaderboard("")
+	var high []LeaderboardEntry
+	for i := 0; i < maxLeaderboardEntries; i++ {
+		high = append(high, LeaderboardEntry{Name: "high", Score: 1000 + i, Mode: modeCoop, Difficulty: defaultDifficulty})
+	}
+	store.Submit(high)
+	// 他の区分の記録は削除されない
+	store.Submit([]LeaderboardEntry{{Name: "hard", Score: 1, Mode: modeCoop, Difficulty: "hard"}})
+	for i := 0; i < maxLeaderboardEntries; i++ {
+		store.Submit([]LeaderboardEntry{{Name: "low", Score: 10, Mode: modeCoop, Difficulty: defaultDifficulty}})
+	}
+
+	top, _ := store.Top(LeaderboardQuery{Mode: modeCoop, Difficulty: defaultDifficulty, Limit: maxLeaderboardEntries + 1})
+	if len(top) != maxLeaderboardEntries {
+		t.Fatalf("記録数が %d", len(top))
+	}
+	for _, e := range top {
+		if e.Name != "high" {
+			t.Fatalf("上位の記録が削除された: %+v", e)
+		}
+	}
+	if hard, _ := store.Top(LeaderboardQuery{Mode: modeCoop, Difficulty: "hard", Limit: 10}); len(hard) != 1 {
+		t.Fatalf("他の区分の記録が削除された: %d", len(hard))
+	}
+}
+
+func TestLeaderboardPruneDailyByClearTime(t *testing.T) {
+	store := newFileLeaderboard("")
+	day := "2024-01-01"
+	for i := 0; i < maxLeaderboardEntries+10; i++ {
+		store.Submit([]LeaderboardEntry{{Name: "run", Mode: modeDaily, Difficulty: defaultDifficulty, Day: day, Cleared: true, ClearTime: 100000 - i}})
+	}
+	top, _ := store.Top(LeaderboardQuery{Mode: modeDaily, Difficulty: defaultDifficulty, Day: day, Fastest: true, Limit: maxLeaderboardEntries + 10})
+	if len(top) != maxLeaderboardEntries {
+		t.Fatalf("記録数が %d", len(top))
+	}
+	// 遅い（先に記録した）10件が削除される
+	if slowest := top[len(top)-1].ClearTime; slowest != 100000-10 {
+		t.Fatalf("最も遅い記録が %d", slowest)
+	}
+}
+
+func TestSubmitScores(t *testing.T) {
+	gameRoom := newGameRoom("hard", modeCoop, defaultRules)
+	scorer := testPlayer(gameRoom, "lb-scorer", 100, 500)
+	scorer.Score = 1200
+	testPlayer(gameRoom, "lb-zero", 200, 500)
+	gameRoom.Level = 2
+	gameRoom.runTicks = 90 * 60
+	gameRoom.GameState = "gameover"
+
+	entries := submitAndWait(t, gameRoom)
+	if len(entries) != 1 {
+		t.Fatalf("登録された記録 %+v, want 得点したプレイヤーの1件", entries)
+	}
+	e := entries[0]
+	if e.PlayerID != scorer.ID || e.Score != 1200 || e.Mode != modeCoop || e.Difficulty != "hard" ||
+		e.Level != 2 || e.Cleared || e.Seconds != 90 {
+		t.Errorf("記録の内容 %+v", e)
+	}
+}
+
+func TestSubmitScoresEndlessUsesWave(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeEndless, defaultRules)
+	p := testPlayer(gameRoom, "lb-endless", 100, 500)
+	p.Score = 5000
+	gameRoom.Level = 7
+	gameRoom.GameState = "gameover"
+
+	entries := submitAndWait(t, gameRoom)
+	if len(entries) != 1 || entries[0].Score != 7 {
+		t.Fatalf("エンドレスの記録 %+v, want 到達ウェーブ7", entries)
+	}
+}
+
+func TestSubmitScoresSkipsUnrankedRooms(t *testing.T) {
+	custom := newGameRoom(defaultDifficulty, modeCoop, RoomRules{MaxPlayers: defaultMaxPlayers, FirePower: 3})
+	testPlayer(custom, "lb-custom", 100, 500).Score = 900
+	custom.GameState = "gameover"
+	if entries := submitAndWait(t, custom); entries != nil {
+		t.Errorf("既定と異なるルールのルームが登録された: %+v", entries)
+	}
+
+	daily := newGameRoom(defaultDifficulty, modeDaily, defaultRules)
+	testPlayer(daily, "lb-daily", 100, 500).Score = 900
+	daily.GameState = "gameover"
+	if entries := submitAndWait(t, daily); entries != nil {
+		t.Errorf("未クリアのデイリーチャレンジが登録された: %+v", entries)
+	}
+
+	daily.GameState = "clear"
+	daily.runTicks = 60 * 60
+	entries := submitAndWait(t, daily)
+	if len(entries) != 1 || !entries[0].Cleared || entries[0].ClearTime != 60000 || entries[0].Day != daily.Day {
+		t.Errorf("クリアしたデイリーチャレンジの記録 %+v", entries)
+	}
+}
+
+func TestHandleLeaderboard(t *testing.T) {
+	store := newFileLeaderboard("")
+	store.Submit([]LeaderboardEntry{
+		{Name: "old", Score: 300, Mode: modeCoop, Difficulty: "hard", At: time.Now().Add(-48 * time.Hour)},
+		{Name: "new", Score: 200, Mode: modeCoop, Difficulty: "hard", At: time.Now()},
+		{Name: "easy", Score: 900, Mode: modeCoop, Difficulty: "easy", At: time.Now()},
+	})
+	saved := leaderboard
+	leaderboard = store
+	defer func() { leaderboard = saved }()
+
+	request := func(query string) (int, []LeaderboardEntry) {
+		req := httptest.NewRequest(http.MethodGet, "/api/leaderboard?"+query, nil)
+		rec := httptest.NewRecorder()
+		if err := handleLeaderboard(echo.New().NewContext(req, rec)); err != nil {
+			if he, ok := err.(*echo.HTTPError); ok {
+				return he.Code, nil
+			}
+			t.Fatalf("%s: %v", query, err)
+		}
+		var body struct {
+			Entries []LeaderboardEntry `json:"entries"`
+		}
+		if err := json.NewDecoder(strings.NewReader(rec.Body.String())).Decode(&body); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return rec.Code, body.Entries
+	}
+
+	for _, query := range []string{"mode=arcade", "difficulty=insane", "period=year", "limit=0", "limit=101", "mode=daily&day=yesterday"} {
+		if code, _ := request(query); code != http.StatusBadRequest {
+			t.Errorf("%s: ステータス %d, want 400", query, code)
+		}
+	}
+
+	code, entries := request("difficulty=hard")
+	if code != http.StatusOK || len(entries) != 2 || entries[0].Name != "old" {
+		t.Errorf("全期間: %d %+v", code, entries)
+	}
+	if _, entries := request("difficulty=hard&period=day"); len(entries) != 1 || entries[0].Name != "new" {
+		t.Errorf("1日以内: %+v", entries)
+	}
+	if _, entries := request("difficulty=hard&limit=1"); len(entries) != 1 || entries[0].Score != 300 {
+		t.Errorf("件数1: %+v", entries)
+	}
+}
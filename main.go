@@ -7,27 +7,40 @@
  * 概要:
  * - WebSocketを使用したリアルタイム通信
  * - 複数プレイヤーが参加可能なゲームルーム管理
- * - 敵の自動生成と衝突検出
+ * - コンポーネントとシステムによるエンティティ管理
+ * - 敵の自動生成と衝突検出（レイヤー・マスクと応答テーブル、グリッドによるブロードフェーズ、形状ごとのナローフェーズ）
  * - 60FPSでのゲームループ処理
- * - ボス敵の実装
+ * - ボス敵の実装（レジストリ・複数レベル・複数体同時出現）
+ * - 難易度選択とプレイヤー数による難易度補正
+ * - 種類別のアイテムとドロップテーブル、時間制限付き効果
+ * - 武器の種類とレベル（拡散・レーザー・ホーミング・レール・チャージ）
  * - クリア・ゲームオーバー画面
+ * - ハイスコアのリーダーボード（JSONファイルに保存）
+ * - アカウント登録・ログインとプロフィールの保存（未ログインならゲスト）
+ * - ルーム内チャットとホストによるミュート・キック
+ * - 味方への合図（位置・敵・アイテムに付けるマーカー）
  *
  * 制限事項:
- * - データの永続化は行わない（インメモリ）
- * - 最大4人までのプレイヤー
+ * - ゲームの状態はインメモリ（永続化するのはリーダーボードとアカウントのみ）
+ * - セッションはインメモリのため、サーバーを再起動すると再ログインが必要
+ * - 1ルーム最大8人まで（既定は4人、ルーム作成時のルールで変更）
  *
  * 必要なパッケージのインストール:
  * - go get github.com/labstack/echo/v4
  * - go get github.com/gorilla/websocket
  * - go get github.com/google/uuid
+ * - go get golang.org/x/crypto
  */
 
 package main
 
 import (
+	"encoding/json"
 	"log"
 	"math/rand"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -56,46 +69,89 @@ var (
 
 /**
  * エンティティ構造体
- * ゲーム内の全てのオブジェクト（プレイヤー、弾、敵）の基本情報
- * @property {string} ID - エンティティの一意識別子
- * @property {string} Type - エンティティの種類（"player", "bullet", "enemy", "boss"）
- * @property {float64} X - X座標位置
- * @property {float64} Y - Y座標位置
- * @property {float64} VelocityX - X方向の速度
- * @property {float64} VelocityY - Y方向の速度
- * @property {int} Width - 幅（ピクセル）
- * @property {int} Height - 高さ（ピクセル）
- * @property {int} Health - エンティティの体力（主にボス用）
+ * ゲーム内の全てのオブジェクト（プレイヤー、弾、敵、ボス、アイテム）
+ * IDと種類に、必要なコンポーネントを組み合わせて構成する（components.go）
+ * @property {EntityID} ID - エンティティのハンドル（プール外のプレイヤー機体は0）
+ * @property {string} Type - エンティティの種類（"player", "bullet", "enemy", "boss", "item"）
+ * @property {string} Kind - 同じ種類の中での区別（ボス名など）
+ * @property {Transform} Transform - 位置と大きさ
+ * @property {*Velocity} Velocity - 速度（nilなら移動しない）
+ * @property {*Collider} Collider - 衝突判定（nilなら衝突しない）
+ * @property {*Health} Health - 体力（nilなら体力を持たない）
+ * @property {*Weapon} Weapon - 武器（プレイヤー用）
+ * @property {*AI} AI - 自律行動（敵・ボス・ホーミング弾）
+ * @property {*Lifetime} Lifetime - 寿命（nilなら画面外に出ても消えない）
+ * @property {*Pickup} Pickup - アイテムとしての効果
+ * @property {bool} removed - 衝突処理中に取り除かれたか
+ * @property {uint64} seq - ルーム内での生成順の通し番号（プール外のプレイヤー機体は0）
  */
 type Entity struct {
-	ID        string  `json:"id"`
-	Type      string  `json:"type"`
-	X         float64 `json:"x"`
-	Y         float64 `json:"y"`
-	VelocityX float64 `json:"velocityX"`
-	VelocityY float64 `json:"velocityY"`
-	Width     int     `json:"width"`
-	Height    int     `json:"height"`
-	Health    int     `json:"health"`
+	ID   EntityID `json:"id"`
+	Type string   `json:"type"`
+	Kind string   `json:"kind,omitempty"`
+	Transform
+	*Velocity
+	*Collider
+	*Health
+	*Weapon
+	*AI
+	*Lifetime
+	*Pickup
+	removed bool
+	seq     uint64
 }
 
 /**
  * プレイヤー構造体
  * プレイヤー固有の情報を保持
- * @property {Entity} Entity - 基本エンティティ情報（継承）
+ * @property {Entity} Entity - 基本エンティティ情報（体力・武器はコンポーネントとして持つ）
+ * @property {string} ID - プレイヤーの一意識別子（クライアントIDと同じUUID）
  * @property {string} Name - プレイヤー名
  * @property {int} Score - スコア
- * @property {int} Health - 体力値
  * @property {string} Color - プレイヤーカラー（16進数カラーコード）
- * @property {int} FirePower - プレイヤーの攻撃力（アイテム取得で増加）
+ * @property {int} Combo - 現在のコンボ数（撃破点に倍率がかかる）
+ * @property {int} comboTimer - コンボが途切れるまでの残りティック数
+ * @property {StageStats} Stage - 現在のレベル内の成績
+ * @property {int} Lives - 残機（倒れたときに消費し、一定時間後にリスポーン）
+ * @property {int} RespawnTicks - リスポーンまでの残りティック数（0ならリスポーン待ちでない）
+ * @property {int} ReviveProgress - 味方による蘇生の進行（ティック数）
+ * @property {int} Invulnerable - 無敵の残りティック数
+ * @property {int} Frags - 他のプレイヤーを倒した数（デスマッチ）
+ * @property {int} Deaths - 倒された回数（デスマッチ）
+ * @property {string} Team - 所属チーム（チーム対戦のみ）
+ * @property {map[string]int} Effects - 時間制限付き効果の残りティック数（キー：効果の種類）
+ * @property {string} account - ログイン中のユーザー名（ゲストは空）
+ * @property {string} identity - キック・ミュートの対象を識別するキー（ログイン中ならアカウント、ゲストならサーバーが発行したゲストID）
+ * @property {time.Time} joinedAt - ルームに参加した時刻（ホストの引き継ぎ順）
+ * @property {[]time.Time} chatTimes - 直近の発言時刻（発言数の制限用）
+ * @property {int} pingCooldown - 次の合図を送れるまでの残りティック数
  */
 type Player struct {
 	Entity
-	Name      string `json:"name"`
-	Score     int    `json:"score"`
-	Health    int    `json:"health"`
-	Color     string `json:"color"`
-	FirePower int    `json:"firePower"`
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Score   int            `json:"score"`
+	Color   string         `json:"color"`
+	Combo   int            `json:"combo"`
+	Lives   int            `json:"lives"`
+	Effects map[string]int `json:"effects"`
+
+	RespawnTicks   int `json:"respawnTicks"`
+	ReviveProgress int `json:"reviveProgress"`
+	Invulnerable   int `json:"invulnerable"`
+
+	Frags  int    `json:"frags"`
+	Deaths int    `json:"deaths"`
+	Team   string `json:"team,omitempty"`
+
+	comboTimer int
+	Stage      StageStats `json:"-"`
+	account    string
+	identity   string
+	joinedAt   time.Time
+	chatTimes  []time.Time
+
+	pingCooldown int
 }
 
 /**
@@ -103,28 +159,76 @@ type Player struct {
  * 一つのゲームインスタンスを表す
  * @property {string} ID - ルームの一意識別子
  * @property {map[string]*Player} Players - プレイヤーマップ（キー：プレイヤーID）
- * @property {map[string]*Entity} Bullets - 弾のマップ（キー：弾ID）
- * @property {map[string]*Entity} Enemies - 敵のマップ（キー：敵ID）
- * @property {*Entity} Boss - ボス敵（存在する場合）
- * @property {map[string]*Entity} Items - アイテムのマップ（キー：アイテムID）
+ * @property {map[EntityID]*Entity} Bullets - 弾のマップ（キー：弾のハンドル）
+ * @property {map[EntityID]*Entity} Enemies - 敵のマップ（キー：敵のハンドル）
+ * @property {map[EntityID]*Entity} Bosses - 出現中のボスのマップ（キー：ボスのハンドル）
+ * @property {map[EntityID]*Entity} Items - アイテムのマップ（キー：アイテムのハンドル）
  * @property {time.Time} LastTick - 最後のゲームティック時間
  * @property {sync.Mutex} Mutex - 同時アクセス防止のミューテックス
  * @property {int} EnemiesDefeated - 倒した敵の数
- * @property {bool} BossSpawned - ボスが出現済みかどうか
- * @property {string} GameState - ゲームの状態（"playing", "gameover", "clear"）
+ * @property {bool} BossSpawned - 現在のレベルのボスが出現済みかどうか
+ * @property {int} Level - 現在のレベル番号（1始まり）
+ * @property {string} Mode - ルーム作成時に選択されたゲームモード（modes.go、リーダーボードの区分）
+ * @property {string} Difficulty - ルーム作成時に選択された難易度
+ * @property {string} Day - デイリーチャレンジの日付（YYYY-MM-DD、他のモードは空）
+ * @property {RoomRules} Rules - ルーム作成時に指定されたルール（rules.go）
+ * @property {string} GameState - ゲームの状態（"playing", "gameover", "clear", "matchover"（デスマッチの試合終了））
+ * @property {*StageResult} Results - 直前に終了したレベルの結果（内訳）
+ * @property {string} Host - ホストのプレイヤーID（ミュート・キックができる）
+ * @property {int} levelTicks - 現在のレベルの経過ティック数
+ * @property {int} runTicks - ゲーム開始からの経過ティック数
+ * @property {[]ScoreEvent} scoreEvents - 次のブロードキャストで送るスコア変化イベント
+ * @property {[]ChatMessage} chat - 直近のチャット履歴
+ * @property {map[string]bool} muted - 発言を禁止されたプレイヤー（キー：identity）
+ * @property {map[string]bool} banned - キックされたプレイヤー（キー：identity、このルームに参加できない）
+ * @property {[]*Ping} pings - 表示中のマーカー（古い順）
+ * @property {int} nextPingID - 最後に割り当てたマーカーの番号
+ * @property {int} matchTicks - 試合時間（デスマッチで2人以上そろっていたティック数）
+ * @property {*MatchResult} matchResult - 終了した試合の結果（デスマッチ、試合中はnil）
+ * @property {*VersusState} versus - チーム対戦の状態（チーム対戦でなければnil）
+ * @property {*rand.Rand} rng - 敵の出現に使う乱数（デイリーチャレンジではその日のシード値）
+ * @property {*rand.Rand} combatRng - 敵・ボスの攻撃とドロップに使う乱数（出現とは別の系列）
+ * @property {int} sharedLives - ルーム全体で共有する残機（ルールで共有するときのみ使う）
+ * @property {map[string]bool} livesShared - 今回のゲームで共有の残機に分を加えたプレイヤー（キー：identity）
+ * @property {*EntityPool} entities - 弾・敵・ボス・アイテムのプール
+ * @property {map[CollisionLayer]*SpatialGrid} grids - レイヤーごとの衝突判定用グリッド（毎ティック再構築）
  */
 type GameRoom struct {
-	ID              string             `json:"id"`
-	Players         map[string]*Player `json:"players"`
-	Bullets         map[string]*Entity `json:"bullets"`
-	Enemies         map[string]*Entity `json:"enemies"`
-	Boss            *Entity            `json:"boss"`
-	Items           map[string]*Entity `json:"items"`
+	ID              string               `json:"id"`
+	Players         map[string]*Player   `json:"players"`
+	Bullets         map[EntityID]*Entity `json:"bullets"`
+	Enemies         map[EntityID]*Entity `json:"enemies"`
+	Bosses          map[EntityID]*Entity `json:"bosses"`
+	Items           map[EntityID]*Entity `json:"items"`
 	LastTick        time.Time
 	Mutex           sync.Mutex
-	EnemiesDefeated int    `json:"enemiesDefeated"`
-	BossSpawned     bool   `json:"bossSpawned"`
-	GameState       string `json:"gameState"`
+	EnemiesDefeated int          `json:"enemiesDefeated"`
+	BossSpawned     bool         `json:"bossSpawned"`
+	Level           int          `json:"level"`
+	Mode            string       `json:"mode"`
+	Difficulty      string       `json:"difficulty"`
+	Day             string       `json:"day,omitempty"`
+	Rules           RoomRules    `json:"rules"`
+	GameState       string       `json:"gameState"`
+	Results         *StageResult `json:"results"`
+	Host            string       `json:"host"`
+	levelTicks      int
+	runTicks        int
+	scoreEvents     []ScoreEvent
+	chat            []ChatMessage
+	muted           map[string]bool
+	banned          map[string]bool
+	pings           []*Ping
+	nextPingID      int
+	matchTicks      int
+	matchResult     *MatchResult
+	versus          *VersusState
+	rng             *rand.Rand
+	combatRng       *rand.Rand
+	sharedLives     int
+	livesShared     map[string]bool
+	entities        *EntityPool
+	grids           map[CollisionLayer]*SpatialGrid
 }
 
 /**
@@ -145,7 +249,7 @@ type Client struct {
 /**
  * WebSocketメッセージ構造体
  * クライアント-サーバー間の通信形式
- * @property {string} Type - メッセージタイプ（"init", "move", "shoot", "gameState"など）
+ * @property {string} Type - メッセージタイプ（"init", "move", "shoot", "chat", "ping", "profile", "gameState", "error"など）
  * @property {interface{}} Data - メッセージデータ（タイプにより内容が異なる）
  */
 type Message struct {
@@ -155,21 +259,40 @@ type Message struct {
 
 /**
  * 新規ゲームルームを作成する
+ * @param {string} difficulty - 難易度名（検証済みであること）
+ * @param {string} mode - ゲームモード名（検証済みであること）
  * @returns {*GameRoom} - 作成されたゲームルームへのポインタ
  */
-func newGameRoom() *GameRoom {
-	return &GameRoom{
+func newGameRoom(difficulty, mode string, rules RoomRules) *GameRoom {
+	gameRoom := &GameRoom{
 		ID:              uuid.New().String(),
 		Players:         make(map[string]*Player),
-		Bullets:         make(map[string]*Entity),
-		Enemies:         make(map[string]*Entity),
-		Boss:            nil,
-		Items:           make(map[string]*Entity),
+		Bullets:         make(map[EntityID]*Entity),
+		Enemies:         make(map[EntityID]*Entity),
+		Bosses:          make(map[EntityID]*Entity),
+		Items:           make(map[EntityID]*Entity),
 		LastTick:        time.Now(),
 		EnemiesDefeated: 0,
 		BossSpawned:     false,
+		Level:           1,
+		Mode:            mode,
+		Difficulty:      difficulty,
+		Rules:           rules,
 		GameState:       "playing",
+		entities:        newEntityPool(),
+		muted:           make(map[string]bool),
+		banned:          make(map[string]bool),
+		livesShared:     make(map[string]bool),
+		rng:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		combatRng:       rand.New(rand.NewSource(time.Now().UnixNano() + 1)),
 	}
+	if gameModes[mode].Teams {
+		gameRoom.versus = newVersusState()
+	}
+	if gameModes[mode].Daily {
+		startDaily(gameRoom)
+	}
+	return gameRoom
 }
 
 /**
@@ -180,9 +303,34 @@ func main() {
 	// 乱数シードの初期化
 	rand.Seed(time.Now().UnixNano())
 
+	// リーダーボードの読み込み
+	leaderboardFile, ok := os.LookupEnv("LEADERBOARD_FILE")
+	if !ok {
+		leaderboardFile = defaultLeaderboardFile
+	}
+	store, err := openFileLeaderboard(leaderboardFile)
+	if err != nil {
+		log.Fatal("リーダーボードの読み込みエラー: ", err)
+	}
+	leaderboard = store
+
+	// アカウントの読み込み
+	accountsFile, ok := os.LookupEnv("ACCOUNTS_FILE")
+	if !ok {
+		accountsFile = defaultAccountsFile
+	}
+	accountStore, err := openFileAccounts(accountsFile)
+	if err != nil {
+		log.Fatal("アカウントの読み込みエラー: ", err)
+	}
+	accounts = accountStore
+
 	// Echoフレームワークの初期化
 	e := echo.New()
 
+	// 接続元IPはクライアントが送る X-Forwarded-For・X-Real-IP ではなく、TCP接続の相手から取る
+	e.IPExtractor = echo.ExtractIPDirect()
+
 	// ミドルウェア設定
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
@@ -194,6 +342,20 @@ func main() {
 	// WebSocketエンドポイント
 	e.GET("/ws", handleWebSocket)
 
+	// リーダーボード
+	e.GET("/api/leaderboard", handleLeaderboard)
+
+	// ルーム一覧
+	e.GET("/api/rooms", handleRooms)
+
+	// アカウント
+	e.POST("/api/register", handleRegister)
+	e.POST("/api/login", handleLogin)
+	e.POST("/api/logout", handleLogout)
+	e.POST("/api/ws-ticket", handleWSTicket)
+	e.GET("/api/profile", handleGetProfile)
+	e.PUT("/api/profile", handleUpdateProfile)
+
 	// サーバー起動（ポート1323）
 	e.Logger.Fatal(e.Start(":1323"))
 }
@@ -205,8 +367,64 @@ func main() {
  * @returns {error} - エラー（あれば）
  */
 func handleWebSocket(c echo.Context) error {
+	// チケットがあればアップグレード前に検証する（なければゲスト）
+	var account *Account
+	if ticket := c.QueryParam("ticket"); ticket != "" {
+		a, err := ticketAccount(ticket)
+		if err != nil {
+			return err
+		}
+		account = &a
+	}
+
+	// 参加時の名前と色（クエリパラメータ、なければログイン中のプロフィール）
+	// 検証に失敗した指定は使わず、参加後にエラーとして知らせる
+	var name, color string
+	var joinErrors []error
+	if account != nil {
+		name, color = account.Profile.DisplayName, account.Profile.Color
+	}
+	if q := c.QueryParam("name"); q != "" {
+		if n, err := validateName(q); err != nil {
+			joinErrors = append(joinErrors, err)
+		} else {
+			name = n
+		}
+	}
+	if q := c.QueryParam("color"); q != "" {
+		if col, err := validateColor(q); err != nil {
+			joinErrors = append(joinErrors, err)
+		} else {
+			color = col
+		}
+	}
+
+	// 希望する難易度とゲームモード（クエリパラメータ、未指定ならnormal・協力プレイ）
+	difficulty := normalizeDifficulty(c.QueryParam("difficulty"))
+	mode := normalizeMode(c.QueryParam("mode"))
+
+	// デイリーチャレンジは難易度をそろえ、同じ日のルームにだけ参加する
+	var day string
+	if gameModes[mode].Daily {
+		difficulty = defaultDifficulty
+		day = challengeDay(time.Now())
+	}
+
+	// 新しくルームを作るときのルール（不正な指定は参加させない）
+	rules, err := parseRules(c, mode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// キック・ミュートの対象を識別するゲストID（なければ発行してアップグレードの応答で設定する）
+	guestID, guestCookie := guestIdentity(c)
+	header := http.Header{}
+	if guestCookie != nil {
+		header.Add("Set-Cookie", guestCookie.String())
+	}
+
 	// WebSocketへのアップグレード
-	ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	ws, err := upgrader.Upgrade(c.Response(), c.Request(), header)
 	if err != nil {
 		log.Println("WebSocketアップグレードエラー:", err)
 		return err
@@ -225,43 +443,68 @@ func handleWebSocket(c echo.Context) error {
 	clients[clientID] = client
 	clientsMutex.Unlock()
 
-	// プレイヤー作成（ランダム色と初期位置）
-	playerColors := []string{"#FF0000", "#00FF00", "#0000FF", "#FFFF00", "#FF00FF"}
+	// プレイヤー作成（ランダムな初期位置、色はルーム参加時に割り当てる）
 	player := &Player{
 		Entity: Entity{
-			ID:        clientID,
 			Type:      "player",
-			X:         float64(300 + rand.Intn(300)),
-			Y:         float64(300 + rand.Intn(300)),
-			VelocityX: 0,
-			VelocityY: 0,
-			Width:     30,
-			Height:    30,
-			Health:    100,
+			Transform: transformAt(float64(300+rand.Intn(300)), float64(300+rand.Intn(300)), 30, 30),
+			Velocity:  &Velocity{Confined: true},
+			Collider:  &Collider{Shape: playerHurtbox},
+			Health:    &Health{Current: maxPlayerHealth, Max: maxPlayerHealth},
+			Weapon:    &Weapon{Name: defaultWeapon, FirePower: 1},
 		},
-		Name:      "Player-" + clientID[:5],
-		Score:     0,
-		Health:    100,
-		Color:     playerColors[rand.Intn(len(playerColors))],
-		FirePower: 1,
+		ID:      clientID,
+		Name:    "Player-" + clientID[:5],
+		Score:   0,
+		Lives:   startingLives,
+		Effects: make(map[string]int),
 	}
+	setLayer(&player.Entity, layerPlayer)
 	client.Player = player
 
+	if name != "" {
+		player.Name = name
+	}
+	player.identity = "guest:" + guestID
+	if account != nil {
+		player.account = account.Username
+		player.identity = "account:" + strings.ToLower(account.Username)
+	}
+
 	// ゲームルーム検索・作成
 	gamesMutex.Lock()
 	var gameRoom *GameRoom
 
-	// 空きのあるルームを探す
+	// ルームの指定があればそのルームに参加する（モード・難易度・ルールはルームのもの）
+	if id := c.QueryParam("room"); id != "" {
+		if room, ok := gameRooms[id]; ok {
+			room.Mutex.Lock()
+			if canJoin(room, player) {
+				gameRoom = room
+			}
+			room.Mutex.Unlock()
+		}
+		if gameRoom == nil {
+			joinErrors = append(joinErrors, errRoomUnavailable)
+		}
+	}
+
+	// 空きのある同じ難易度・モード・ルールのルームを探す（プレイ中のみ、キックされたルームには入らない）
 	for _, room := range gameRooms {
-		if len(room.Players) < 4 && room.GameState == "playing" { // 最大4人、プレイ中のルームのみ
-			gameRoom = room
+		if gameRoom != nil {
 			break
 		}
+		room.Mutex.Lock()
+		joinable := canJoin(room, player) && room.Difficulty == difficulty && room.Mode == mode && room.Day == day && room.Rules == rules
+		room.Mutex.Unlock()
+		if joinable {
+			gameRoom = room
+		}
 	}
 
 	// 空きがなければ新規ルーム作成
 	if gameRoom == nil {
-		gameRoom = newGameRoom()
+		gameRoom = newGameRoom(difficulty, mode, rules)
 		gameRooms[gameRoom.ID] = gameRoom
 		go gameLoop(gameRoom) // ゲームループ開始
 	}
@@ -269,23 +512,43 @@ func handleWebSocket(c echo.Context) error {
 
 	client.GameRoom = gameRoom
 
-	// ルームにプレイヤー追加
+	// ルームにプレイヤー追加（希望の色が他のプレイヤーと重なれば空いている色にする）
 	gameRoom.Mutex.Lock()
+	player.Color = assignColor(gameRoom, player, color)
+	if gameRoom.versus != nil {
+		joinTeam(gameRoom, player) // チームの色が優先
+	}
+	player.FirePower = gameRoom.Rules.FirePower
+	addSharedLives(gameRoom, player)
+	player.joinedAt = time.Now()
 	gameRoom.Players[player.ID] = player
+	if gameRoom.Host == "" {
+		gameRoom.Host = player.ID
+	}
+	history := chatHistory(gameRoom)
 	gameRoom.Mutex.Unlock()
 
 	// 初期状態送信
 	initMsg := Message{
 		Type: "init",
 		Data: map[string]interface{}{
-			"player":   player,
-			"gameRoom": gameRoom.ID,
+			"player":     player,
+			"gameRoom":   gameRoom.ID,
+			"difficulty": gameRoom.Difficulty,
+			"mode":       gameRoom.Mode,
+			"rules":      gameRoom.Rules,
+			"account":    player.account,
+			"chat":       history,
 		},
 	}
-	if err := ws.WriteJSON(initMsg); err != nil {
+	if err := sendMessage(client, initMsg); err != nil {
 		log.Println("初期状態送信エラー:", err)
 		return err
 	}
+	for _, err := range joinErrors {
+		sendMessage(client, Message{Type: "error", Data: map[string]string{"message": err.Error()}})
+	}
+	postSystemChat(gameRoom, player.Name+" が参加しました")
 
 	// メッセージ処理ループ
 	for {
@@ -297,46 +560,125 @@ func handleWebSocket(c echo.Context) error {
 			// 切断処理
 			gameRoom.Mutex.Lock()
 			delete(gameRoom.Players, player.ID)
+			newHost := reassignHost(gameRoom)
+			remaining := len(gameRoom.Players)
 			gameRoom.Mutex.Unlock()
 
 			clientsMutex.Lock()
 			delete(clients, clientID)
 			clientsMutex.Unlock()
 
+			if remaining > 0 {
+				postSystemChat(gameRoom, player.Name+" が退出しました")
+				if newHost != "" {
+					postSystemChat(gameRoom, newHost+" がホストになりました")
+				}
+			}
+
 			break
 		}
 
 		// メッセージタイプによる処理分岐
 		switch msg.Type {
 		case "move":
+			// 倒れている間は動けない
 			if data, ok := msg.Data.(map[string]interface{}); ok {
-				if vx, ok := data["vx"].(float64); ok {
-					player.VelocityX = vx
-				}
-				if vy, ok := data["vy"].(float64); ok {
-					player.VelocityY = vy
+				gameRoom.Mutex.Lock()
+				if isAlive(player) {
+					if vx, ok := data["vx"].(float64); ok {
+						player.VelocityX = vx
+					}
+					if vy, ok := data["vy"].(float64); ok {
+						player.VelocityY = vy
+					}
 				}
+				gameRoom.Mutex.Unlock()
 			}
 		case "shoot":
 			createBullet(gameRoom, player)
+		case "chat":
+			if data, ok := msg.Data.(map[string]interface{}); ok {
+				text, _ := data["text"].(string)
+				if err := postChat(gameRoom, player, text); err != nil {
+					sendMessage(client, Message{Type: "error", Data: map[string]string{"message": err.Error()}})
+				}
+			}
+		case "mute", "kick":
+			// ホストによるモデレーション（mute は "muted": false で解除）
+			if data, ok := msg.Data.(map[string]interface{}); ok {
+				target, _ := data["playerId"].(string)
+				var err error
+				if msg.Type == "kick" {
+					err = kickPlayer(gameRoom, player, target)
+				} else {
+					muted, ok := data["muted"].(bool)
+					err = setMuted(gameRoom, player, target, muted || !ok)
+				}
+				if err != nil {
+					sendMessage(client, Message{Type: "error", Data: map[string]string{"message": err.Error()}})
+				}
+			}
+		case "ping":
+			// 合図（位置に敵・ボス・アイテムがあればそれに付ける）
+			if data, ok := msg.Data.(map[string]interface{}); ok {
+				x, okX := data["x"].(float64)
+				y, okY := data["y"].(float64)
+				callout, _ := data["callout"].(string)
+				if okX && okY {
+					if err := placePing(gameRoom, player, x, y, callout); err != nil {
+						sendMessage(client, Message{Type: "error", Data: map[string]string{"message": err.Error()}})
+					}
+				}
+			}
+		case "profile":
+			// 名前・色の変更（変更しない項目は省略できる）
+			if data, ok := msg.Data.(map[string]interface{}); ok {
+				newName, _ := data["name"].(string)
+				newColor, _ := data["color"].(string)
+				if err := changeProfile(gameRoom, player, newName, newColor); err != nil {
+					sendMessage(client, Message{Type: "error", Data: map[string]string{"message": err.Error()}})
+				}
+			}
 		case "restart":
 			// ゲームが終了状態の場合、再スタート
-			if gameRoom.GameState == "gameover" || gameRoom.GameState == "clear" {
+			if gameRoom.GameState == "gameover" || gameRoom.GameState == "clear" || gameRoom.GameState == "matchover" {
 				gameRoom.Mutex.Lock()
 				gameRoom.GameState = "playing"
 				gameRoom.EnemiesDefeated = 0
 				gameRoom.BossSpawned = false
-				gameRoom.Boss = nil
-				gameRoom.Enemies = make(map[string]*Entity)
-				gameRoom.Bullets = make(map[string]*Entity)
+				gameRoom.Level = 1
+				gameRoom.Results = nil
+				gameRoom.levelTicks = 0
+				gameRoom.runTicks = 0
+				gameRoom.matchTicks = 0
+				gameRoom.pings = nil
+				gameRoom.matchResult = nil
+				despawnAll(gameRoom, gameRoom.Bosses)
+				despawnAll(gameRoom, gameRoom.Enemies)
+				despawnAll(gameRoom, gameRoom.Bullets)
+				despawnAll(gameRoom, gameRoom.Items)
+				gameRoom.entities.Flush()
 
 				// プレイヤーの状態をリセット
 				for _, p := range gameRoom.Players {
-					p.Health = 100
+					revivePlayer(p, maxPlayerHealth)
+					p.Invulnerable = 0
 					p.Score = 0
+					p.Lives = startingLives
+					p.FirePower = gameRoom.Rules.FirePower
+					p.Frags, p.Deaths = 0, 0
+					p.Effects = make(map[string]int)
+					resetStage(p)
 					p.X = float64(300 + rand.Intn(300))
 					p.Y = float64(300 + rand.Intn(300))
 				}
+				resetSharedLives(gameRoom)
+				if gameRoom.versus != nil {
+					resetVersus(gameRoom)
+				}
+				if roomMode(gameRoom).Daily {
+					startDaily(gameRoom) // 同じ並びで最初から（日付が変わっていればその日のチャレンジ）
+				}
 				gameRoom.Mutex.Unlock()
 			}
 		}
@@ -346,94 +688,31 @@ func handleWebSocket(c echo.Context) error {
 }
 
 /**
- * プレイヤーの FirePower に応じて複数弾を拡散発射
- * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
- * @param {*Player} player - 弾を発射するプレイヤーへのポインタ
- */
-func createBullet(gameRoom *GameRoom, player *Player) {
-	if gameRoom.GameState != "playing" {
-		return
-	}
-	for i := 0; i < player.FirePower; i++ {
-		id := uuid.New().String()
-		// 簡易的に左右に拡散させるオフセット
-		offset := float64(i-(player.FirePower-1)/2) * 5
-		b := &Entity{
-			ID:        id,
-			Type:      "bullet",
-			X:         player.X + float64(player.Width)/2 - 2.5 + offset,
-			Y:         player.Y,
-			VelocityX: offset * 0.2,
-			VelocityY: -6,
-			Width:     5,
-			Height:    10,
-		}
-		gameRoom.Mutex.Lock()
-		gameRoom.Bullets[id] = b
-		gameRoom.Mutex.Unlock()
-	}
-}
-
-/**
- * 敵の作成
- * ランダムな位置と速度で敵を生成する
- * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * 衝突判定
+ * グリッドで絞り込んだ候補に対するナローフェーズとして使用する。
+ * 矩形で早期に除外した後、衝突形状を持つエンティティは形状同士で判定する
+ * @param {Entity} a - エンティティA
+ * @param {Entity} b - エンティティB
+ * @returns {bool} - 衝突している場合true
  */
-func createEnemy(gameRoom *GameRoom) {
-	// ゲームがプレイ中でボスが出現していない場合のみ敵を生成
-	if gameRoom.GameState != "playing" || gameRoom.BossSpawned {
-		return
-	}
-
-	enemyID := uuid.New().String()
-	enemy := &Entity{
-		ID:        enemyID,
-		Type:      "enemy",
-		X:         float64(rand.Intn(600)),
-		Y:         0,
-		VelocityX: float64(rand.Intn(3) - 1),
-		VelocityY: float64(rand.Intn(2) + 1),
-		Width:     30,
-		Height:    30,
-		Health:    1,
+func checkCollision(a, b *Entity) bool {
+	if !checkAABB(a, b) {
+		return false
 	}
-
-	gameRoom.Mutex.Lock()
-	gameRoom.Enemies[enemyID] = enemy
-	gameRoom.Mutex.Unlock()
-}
-
-/**
- * ボスの作成
- * 画面上部中央に強力なボスを生成する
- * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
- */
-func createBoss(gameRoom *GameRoom) {
-	boss := &Entity{
-		ID:        "boss-" + uuid.New().String(),
-		Type:      "boss",
-		X:         float64(400 - 50), // 画面中央
-		Y:         50,                // 上部
-		VelocityX: 2,                 // 左右に移動
-		VelocityY: 0,
-		Width:     100,
-		Height:    80,
-		Health:    100, // ボスの体力
+	if a.Shape == nil && b.Shape == nil {
+		return true
 	}
-
-	gameRoom.Mutex.Lock()
-	gameRoom.Boss = boss
-	gameRoom.BossSpawned = true
-	gameRoom.Mutex.Unlock()
+	return shapesOverlap(a, b)
 }
 
 /**
- * 衝突判定（AABB: Axis-Aligned Bounding Box）
+ * 矩形同士の衝突判定（AABB: Axis-Aligned Bounding Box）
+ * 衝突形状を無視して Width/Height の矩形で判定する（アイテム取得など）
  * @param {Entity} a - エンティティA
  * @param {Entity} b - エンティティB
  * @returns {bool} - 衝突している場合true
  */
-func checkCollision(a, b *Entity) bool {
+func checkAABB(a, b *Entity) bool {
 	return a.X < b.X+float64(b.Width) &&
 		a.X+float64(a.Width) > b.X &&
 		a.Y < b.Y+float64(b.Height) &&
@@ -446,8 +725,8 @@ func checkCollision(a, b *Entity) bool {
  * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
  */
 func gameLoop(gameRoom *GameRoom) {
-	ticker := time.NewTicker(time.Second / 60)     // 60FPS
-	enemyTicker := time.NewTicker(time.Second * 2) // 2秒ごとに敵生成
+	ticker := time.NewTicker(time.Second / 60)                  // 60FPS
+	enemyTicker := time.NewTicker(enemySpawnInterval(gameRoom)) // 難易度と人数に応じた間隔で敵生成
 
 	defer ticker.Stop()
 	defer enemyTicker.Stop()
@@ -468,301 +747,141 @@ func gameLoop(gameRoom *GameRoom) {
 			}
 
 		case <-enemyTicker.C:
-			// プレイ中のみ敵を生成
-			if gameRoom.GameState == "playing" {
+			// 敵が出現するモードのプレイ中のみ敵を生成
+			if gameRoom.GameState == "playing" && roomMode(gameRoom).Enemies {
 				// 一定数の敵を倒したらボス出現
-				if gameRoom.EnemiesDefeated >= 20 && !gameRoom.BossSpawned {
+				level := currentLevel(gameRoom)
+				if roomMode(gameRoom).Bosses && len(level.Bosses) > 0 && gameRoom.EnemiesDefeated >= level.EnemiesToBoss && !gameRoom.BossSpawned {
 					createBoss(gameRoom)
 				} else {
 					createEnemy(gameRoom)
 				}
 			}
+			// 参加人数の変化に合わせて出現間隔を更新
+			enemyTicker.Reset(enemySpawnInterval(gameRoom))
 		}
 	}
 }
 
 /**
  * ゲーム状態更新
- * エンティティの移動や衝突判定などのゲームロジックを処理する
+ * 登録順にシステムを実行する（systems.go）
+ * レベルクリアやゲームオーバーで状態が変わったら、そのティックの残りのシステムは実行しない
  * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
  */
 func updateGame(gameRoom *GameRoom) {
 	gameRoom.Mutex.Lock()
 	defer gameRoom.Mutex.Unlock()
+	// 取り除いたエンティティはティックの最後にプールへ返却
+	defer gameRoom.entities.Flush()
 
 	// ゲームがプレイ中でない場合は更新しない
 	if gameRoom.GameState != "playing" {
 		return
 	}
 
-	// プレイヤー移動
-	for _, player := range gameRoom.Players {
-		player.X += player.VelocityX
-		player.Y += player.VelocityY
-
-		// 画面端の衝突判定
-		if player.X < 0 {
-			player.X = 0
-		}
-		if player.X > 770 {
-			player.X = 770
-		}
-		if player.Y < 0 {
-			player.Y = 0
-		}
-		if player.Y > 570 {
-			player.Y = 570
-		}
-	}
-
-	// 敵がランダムに撃つ
-	for _, enemy := range gameRoom.Enemies {
-		if rand.Intn(1000) < 5 { // 確率調整
-			bid := uuid.New().String()
-			eb := &Entity{
-				ID:        bid,
-				Type:      "enemyBullet",
-				X:         enemy.X + float64(enemy.Width)/2,
-				Y:         enemy.Y + float64(enemy.Height),
-				VelocityX: 0,
-				VelocityY: 3,
-				Width:     5,
-				Height:    5,
-			}
-			gameRoom.Bullets[bid] = eb
-		}
-	}
-
-	// 全弾を移動＆衝突判定
-	for id, b := range gameRoom.Bullets {
-		b.X += b.VelocityX
-		b.Y += b.VelocityY
-
-		// 画面外削除
-		if b.Y < 0 || b.Y > 600 || b.X < 0 || b.X > 800 {
-			delete(gameRoom.Bullets, id)
-			continue
-		}
-
-		// 敵／ボス→プレイヤー弾 の当たり判定
-		if b.Type == "enemyBullet" || b.Type == "bossBullet" {
-			for _, p := range gameRoom.Players {
-				if checkCollision(b, &p.Entity) {
-					delete(gameRoom.Bullets, id)
-					p.Health -= 15
-					if p.Health < 0 {
-						p.Health = 0
-					}
-					// 全滅チェック
-					allDead := true
-					for _, q := range gameRoom.Players {
-						if q.Health > 0 {
-							allDead = false
-							break
-						}
-					}
-					if allDead {
-						gameRoom.GameState = "gameover"
-					}
-					break
-				}
-			}
-			continue
-		}
-
-		// プレイヤー弾 の既存処理＋アイテム生成
-		if b.Type == "bullet" {
-			// ボスとの衝突判定
-			if gameRoom.Boss != nil && checkCollision(b, gameRoom.Boss) {
-				// 衝突したら弾を削除、ボスにダメージ
-				delete(gameRoom.Bullets, id)
-				gameRoom.Boss.Health -= 1
-
-				// ボスを倒したらクリア
-				if gameRoom.Boss.Health <= 0 {
-					gameRoom.GameState = "clear"
-					gameRoom.Boss = nil
-
-					// 全プレイヤーにボーナススコア
-					for _, player := range gameRoom.Players {
-						player.Score += 500
-					}
-				}
-				continue
-			}
-
-			// 敵との衝突判定
-			for eid, e := range gameRoom.Enemies {
-				if checkCollision(b, e) {
-					delete(gameRoom.Bullets, id)
-					delete(gameRoom.Enemies, eid)
-					gameRoom.EnemiesDefeated++
-
-					// 敵倒時にアイテムを落とす
-					itemID := uuid.New().String()
-					it := &Entity{
-						ID:        itemID,
-						Type:      "item",
-						X:         e.X,
-						Y:         e.Y,
-						VelocityX: 0,
-						VelocityY: 1,
-						Width:     15,
-						Height:    15,
-						Health:    0,
-					}
-					gameRoom.Items[itemID] = it
-
-					// スコア加算
-					for _, player := range gameRoom.Players {
-						if player.X == b.X && player.Y == b.Y {
-							player.Score += 10
-							break
-						}
-					}
-					break
-				}
-			}
-			continue
-		}
-	}
-
-	// アイテム落下＆取得判定
-	for iid, it := range gameRoom.Items {
-		it.Y += it.VelocityY
-		if it.Y > 600 {
-			delete(gameRoom.Items, iid)
-			continue
-		}
-		for _, p := range gameRoom.Players {
-			if checkCollision(it, &p.Entity) {
-				// 取得で発射能力アップ
-				p.FirePower++
-				delete(gameRoom.Items, iid)
-				break
-			}
-		}
-	}
-
-	// 敵の移動
-	for id, enemy := range gameRoom.Enemies {
-		enemy.X += enemy.VelocityX
-		enemy.Y += enemy.VelocityY
-
-		// 画面外に出たら削除
-		if enemy.Y > 600 {
-			delete(gameRoom.Enemies, id)
-			continue
-		}
-
-		// プレイヤーとの衝突判定
-		for _, player := range gameRoom.Players {
-			if checkCollision(enemy, &player.Entity) {
-				// 衝突したらダメージ
-				player.Health -= 10
-				if player.Health <= 0 {
-					player.Health = 0
-
-					// 全プレイヤーが死亡したらゲームオーバー
-					allPlayersDead := true
-					for _, p := range gameRoom.Players {
-						if p.Health > 0 {
-							allPlayersDead = false
-							break
-						}
-					}
-
-					if allPlayersDead {
-						gameRoom.GameState = "gameover"
-					}
-				}
-				delete(gameRoom.Enemies, id)
-				break
-			}
-		}
-	}
-
-	// ボスの移動と攻撃
-	if gameRoom.Boss != nil {
-		// 左右移動
-		gameRoom.Boss.X += gameRoom.Boss.VelocityX
-
-		// 画面端で反転
-		if gameRoom.Boss.X <= 0 || gameRoom.Boss.X+float64(gameRoom.Boss.Width) >= 800 {
-			gameRoom.Boss.VelocityX *= -1
-		}
-
-		// ランダムで攻撃（ボスの弾発射）
-		if rand.Intn(60) < 5 { // 約1/12の確率で発射
-			bulletID := uuid.New().String()
-			bullet := &Entity{
-				ID:        bulletID,
-				Type:      "bossBullet",
-				X:         gameRoom.Boss.X + float64(gameRoom.Boss.Width)/2,
-				Y:         gameRoom.Boss.Y + float64(gameRoom.Boss.Height),
-				VelocityX: float64(rand.Intn(5) - 2), // ランダムな水平速度
-				VelocityY: float64(rand.Intn(3) + 2), // 下向きに発射
-				Width:     10,
-				Height:    10,
-			}
-			gameRoom.Bullets[bulletID] = bullet
-		}
-
-		// プレイヤーとの衝突判定
-		for _, player := range gameRoom.Players {
-			if checkCollision(gameRoom.Boss, &player.Entity) {
-				// 衝突したら大ダメージ
-				player.Health -= 20
-				if player.Health <= 0 {
-					player.Health = 0
-
-					// 全プレイヤーが死亡したらゲームオーバー
-					allPlayersDead := true
-					for _, p := range gameRoom.Players {
-						if p.Health > 0 {
-							allPlayersDead = false
-							break
-						}
-					}
-
-					if allPlayersDead {
-						gameRoom.GameState = "gameover"
-					}
-				}
-			}
+	level := gameRoom.Level
+	for _, system := range systems {
+		system.Update(gameRoom)
+		if gameRoom.GameState != "playing" || gameRoom.Level != level {
+			return
 		}
 	}
 }
 
 /**
  * ゲーム状態のブロードキャスト
- * 現在のゲーム状態を全プレイヤーに送信する
+ * 現在のゲーム状態を全プレイヤーに送信する（チーム対戦のマーカーはチームごと）。
+ * プールのエンティティは再利用されるため、ロック中にJSONへ変換してから送信する
  * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
  */
 func broadcastGameState(gameRoom *GameRoom) {
 	gameRoom.Mutex.Lock()
 	state := map[string]interface{}{
 		"players":         gameRoom.Players,
-		"bullets":         gameRoom.Bullets,
-		"enemies":         gameRoom.Enemies,
-		"boss":            gameRoom.Boss,
-		"items":           gameRoom.Items,
+		"bullets":         entityList(gameRoom.Bullets),
+		"enemies":         entityList(gameRoom.Enemies),
+		"bosses":          entityList(gameRoom.Bosses),
+		"items":           entityList(gameRoom.Items),
 		"gameState":       gameRoom.GameState,
 		"enemiesDefeated": gameRoom.EnemiesDefeated,
+		"enemiesToBoss":   currentLevel(gameRoom).EnemiesToBoss,
+		"level":           gameRoom.Level,
+		"scoreEvents":     takeScoreEvents(gameRoom),
+		"results":         gameRoom.Results,
+		"host":            gameRoom.Host,
+		"pings":           gameRoom.pings,
+		"mode":            gameRoom.Mode,
+		"match":           matchStatus(gameRoom),
+		"versus":          gameRoom.versus,
+		"day":             gameRoom.Day,
+		"rules":           gameRoom.Rules,
+		"sharedLives":     gameRoom.sharedLives,
+		"runTime":         runMillis(gameRoom),
+	}
+	if gameRoom.versus == nil {
+		data, err := json.Marshal(Message{
+			Type: "gameState",
+			Data: state,
+		})
+		gameRoom.Mutex.Unlock()
+		if err != nil {
+			log.Println("ゲーム状態のエンコードエラー:", err)
+			return
+		}
+		broadcastData(gameRoom, data)
+		return
+	}
+
+	// チーム対戦ではマーカーを味方にだけ見せるため、チームごとにエンコードする
+	byTeam := make(map[string][]byte, len(gameRoom.versus.Teams))
+	for _, team := range gameRoom.versus.Teams {
+		state["pings"] = teamPings(gameRoom, team.Name)
+		data, err := json.Marshal(Message{
+			Type: "gameState",
+			Data: state,
+		})
+		if err != nil {
+			gameRoom.Mutex.Unlock()
+			log.Println("ゲーム状態のエンコードエラー:", err)
+			return
+		}
+		byTeam[team.Name] = data
+	}
+	payloads := make(map[*Player][]byte, len(gameRoom.Players))
+	for _, p := range gameRoom.Players {
+		payloads[p] = byTeam[p.Team]
 	}
 	gameRoom.Mutex.Unlock()
+	broadcastPlayerData(gameRoom, payloads)
+}
 
-	message := Message{
-		Type: "gameState",
-		Data: state,
+/**
+ * メッセージをルームの全プレイヤーに送信する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {Message} msg - メッセージ
+ */
+func broadcastMessage(gameRoom *GameRoom, msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Println("メッセージのエンコードエラー:", err)
+		return
 	}
+	broadcastData(gameRoom, data)
+}
 
-	// 各クライアントに送信
+/**
+ * エンコード済みのメッセージをルームの全プレイヤーに送信する
+ * 書き込みが重ならないよう、クライアント管理のロック中に送信する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {[]byte} data - JSONにエンコードしたメッセージ
+ */
+func broadcastData(gameRoom *GameRoom, data []byte) {
 	clientsMutex.Lock()
 	for id, client := range clients {
 		// このゲームルームに属しているクライアントのみに送信
 		if client.GameRoom != nil && client.GameRoom.ID == gameRoom.ID {
-			err := client.Socket.WriteJSON(message)
+			err := client.Socket.WriteMessage(websocket.TextMessage, data)
 			if err != nil {
 				log.Println("ブロードキャストエラー:", err, "クライアントID:", id)
 			}
@@ -770,3 +889,52 @@ func broadcastGameState(gameRoom *GameRoom) {
 	}
 	clientsMutex.Unlock()
 }
+
+/**
+ * プレイヤーごとにエンコード済みのメッセージを送信する
+ * 送信するデータのないクライアントには何も送らない
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {map[*Player][]byte} payloads - プレイヤーごとのJSONにエンコードしたメッセージ
+ */
+func broadcastPlayerData(gameRoom *GameRoom, payloads map[*Player][]byte) {
+	clientsMutex.Lock()
+	for id, client := range clients {
+		if client.GameRoom == nil || client.GameRoom.ID != gameRoom.ID {
+			continue
+		}
+		data, ok := payloads[client.Player]
+		if !ok {
+			continue
+		}
+		if err := client.Socket.WriteMessage(websocket.TextMessage, data); err != nil {
+			log.Println("ブロードキャストエラー:", err, "クライアントID:", id)
+		}
+	}
+	clientsMutex.Unlock()
+}
+
+/**
+ * メッセージを1つのクライアントに送信する
+ * ブロードキャストと書き込みが重ならないよう、クライアント管理のロック中に送信する
+ * @param {*Client} client - 送信先のクライアント
+ * @param {Message} msg - メッセージ
+ * @returns {error} - 送信エラー（あれば）
+ */
+func sendMessage(client *Client, msg Message) error {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	return client.Socket.WriteJSON(msg)
+}
+
+/**
+ * エンティティのマップを送信用の配列にする
+ * @param {map[EntityID]*Entity} entities - エンティティのマップ
+ * @returns {[]*Entity} - エンティティの配列
+ */
+func entityList(entities map[EntityID]*Entity) []*Entity {
+	list := make([]*Entity, 0, len(entities))
+	for _, e := range entities {
+		list = append(list, e)
+	}
+	return list
+}
@@ -0,0 +1,79 @@
+/**
+ * @file modes.go
+ * @description ゲームモードの定義
+ *
+ * 概要:
+ * - ルーム作成時に選択するゲームモード（協力プレイ / デスマッチ / チーム対戦 / エンドレス / デイリーチャレンジ）
+ * - 同じモード・難易度のルームにだけ参加する
+ * - リーダーボードはモードごとに区分する
+ */
+
+package main
+
+// ゲームモード名
+const (
+	modeCoop       = "coop"       // 協力プレイ
+	modeDeathmatch = "deathmatch" // プレイヤー同士の対戦
+	modeVersus     = "versus"     // 2対2のチーム対戦
+	modeEndless    = "endless"    // 終わりのないウェーブに耐える協力プレイ
+	modeDaily      = "daily"      // 日替わりのタイムアタック
+)
+
+// 既定のゲームモード
+const defaultMode = modeCoop
+
+/**
+ * ゲームモード構造体
+ * @property {string} Name - モード名
+ * @property {bool} PvP - trueならプレイヤーの弾が他のプレイヤーに当たる
+ * @property {bool} Teams - trueならプレイヤーをチームに分け、チームごとに自陣を守る（versus.go）
+ * @property {bool} Enemies - trueなら敵が一定間隔（実時間）で出現する
+ * @property {bool} Bosses - trueなら一定数の敵を倒すとボスが出現する
+ * @property {bool} FreeRespawn - trueなら倒れても残機を消費せず必ずリスポーンする（味方の蘇生・ゲームオーバーはない）
+ * @property {bool} Endless - trueならレベルの代わりにウェーブが続き、到達したウェーブがスコアになる（endless.go）
+ * @property {bool} Daily - trueならその日のシード値とティック数で敵が出現し、クリアタイムを競う（daily.go）
+ */
+type GameMode struct {
+	Name        string
+	PvP         bool
+	Teams       bool
+	Enemies     bool
+	Bosses      bool
+	FreeRespawn bool
+	Endless     bool
+	Daily       bool
+}
+
+// 選択可能なゲームモード（キー：モード名）
+var gameModes = map[string]GameMode{
+	modeCoop:       {Name: modeCoop, Enemies: true, Bosses: true},
+	modeDeathmatch: {Name: modeDeathmatch, PvP: true, FreeRespawn: true},
+	modeVersus:     {Name: modeVersus, Teams: true, Enemies: true, FreeRespawn: true},
+	modeEndless:    {Name: modeEndless, Enemies: true, Bosses: true, Endless: true},
+	modeDaily:      {Name: modeDaily, Bosses: true, Daily: true},
+}
+
+/**
+ * モード名を検証する
+ * 未知のモード名の場合は既定のモードを返す
+ * @param {string} name - クライアントから指定されたモード名
+ * @returns {string} - 有効なモード名
+ */
+func normalizeMode(name string) string {
+	if _, ok := gameModes[name]; ok {
+		return name
+	}
+	return defaultMode
+}
+
+/**
+ * ルームのゲームモードを取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @returns {GameMode} - ゲームモード
+ */
+func roomMode(gameRoom *GameRoom) GameMode {
+	if m, ok := gameModes[gameRoom.Mode]; ok {
+		return m
+	}
+	return gameModes[defaultMode]
+}
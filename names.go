@@ -0,0 +1,244 @@
+/**
+ * @file names.go
+ * @description プレイヤー名・色の検証と禁止語フィルタ（チャットの伏せ字処理にも使う）
+ *
+ * 概要:
+ * - 名前は前後の空白を除き、連続する空白を1つにまとめ、長さ・制御文字・禁止語を検証する
+ * - 色は #RRGGBB 形式のみ受け付け、大文字にそろえる
+ * - 同じルーム内で色が重ならないように、使われていない色を割り当てる
+ * - 禁止語は大文字・小文字や記号・数字による言い換え（"5h1t" など）を正規化してから照合する
+ */
+
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// 名前の最大文字数
+	maxDisplayNameLength = 16
+	// 色の明るさの下限（0〜255、黒い背景で見えるように）
+	minColorLuminance = 60
+)
+
+var (
+	// 色の形式（#RRGGBB）
+	colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+	errNameEmpty    = errors.New("名前を入力してください")
+	errNameTooLong  = errors.New("名前は16文字以内にしてください")
+	errNameInvalid  = errors.New("名前に使えない文字が含まれています")
+	errNameProfane  = errors.New("名前に不適切な言葉が含まれています")
+	errColorInvalid = errors.New("色は #RRGGBB の形式で指定してください")
+	errColorTaken   = errors.New("その色は同じルームの他のプレイヤーが使っています")
+	errColorTooDark = errors.New("その色は背景と見分けにくいため使えません")
+)
+
+// 既定の色（参加時に指定がなければ、ルーム内で使われていない色から選ぶ）
+var playerColors = []string{"#FF0000", "#00FF00", "#3399FF", "#FFFF00", "#FF00FF", "#00FFFF", "#FF8800", "#FFFFFF"}
+
+// 禁止語（正規化後の文字列に含まれていれば不適切とみなす）
+var bannedWords = []string{
+	"fuck", "shit", "bitch", "cunt", "asshole", "bastard", "dick", "pussy",
+	"nigger", "nigga", "faggot", "retard", "whore", "slut",
+	"死ね", "しね", "殺す", "ころす", "きちがい", "キチガイ", "ちんこ", "まんこ",
+}
+
+// 言い換えに使われる記号・数字と、対応する文字
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "@", "a", "$", "s", "!", "i",
+)
+
+/**
+ * 禁止語照合用に文字列を正規化する
+ * 小文字にし、言い換えの記号・数字を文字に戻してから、文字以外を取り除く
+ * @param {string} s - 文字列
+ * @returns {string} - 正規化した文字列
+ */
+func normalizeForFilter(s string) string {
+	s = leetReplacer.Replace(strings.ToLower(s))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+/**
+ * 文字列に禁止語が含まれるか
+ * 部分一致のため、禁止語を含む無害な単語も弾くことがある
+ * @param {string} s - 文字列
+ * @returns {bool} - 含まれていればtrue
+ */
+func containsBannedWord(s string) bool {
+	normalized := normalizeForFilter(s)
+	for _, word := range bannedWords {
+		if strings.Contains(normalized, word) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 禁止語を含む単語を伏せ字にする（空白で区切った単位で判定し、連続する空白は1つにまとめる）
+ * @param {string} text - 文字列
+ * @returns {string} - 伏せ字にした文字列
+ */
+func censorBannedWords(text string) string {
+	words := strings.Fields(text)
+	for i, w := range words {
+		if containsBannedWord(w) {
+			words[i] = strings.Repeat("*", utf8.RuneCountInString(w))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+/**
+ * プレイヤー名を検証する
+ * @param {string} name - 入力された名前
+ * @returns {string, error} - 整形した名前と検証エラー
+ */
+func validateName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", errNameEmpty
+	}
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return "", errNameTooLong
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return "", errNameInvalid
+		}
+	}
+	if containsBannedWord(name) {
+		return "", errNameProfane
+	}
+	return name, nil
+}
+
+/**
+ * 色を検証する
+ * @param {string} color - 入力された色
+ * @returns {string, error} - 大文字にそろえた色と検証エラー
+ */
+func validateColor(color string) (string, error) {
+	if !colorPattern.MatchString(color) {
+		return "", errColorInvalid
+	}
+	color = strings.ToUpper(color)
+	if colorLuminance(color) < minColorLuminance {
+		return "", errColorTooDark
+	}
+	return color, nil
+}
+
+/**
+ * 色の明るさを求める（#RRGGBB 形式であること）
+ * @param {string} color - 色
+ * @returns {int} - 明るさ（0〜255）
+ */
+func colorLuminance(color string) int {
+	v, _ := strconv.ParseUint(color[1:], 16, 32)
+	r, g, b := int(v>>16&0xFF), int(v>>8&0xFF), int(v&0xFF)
+	return (299*r + 587*g + 114*b) / 1000
+}
+
+/**
+ * 色がルーム内の他のプレイヤーに使われているか
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {string} color - 色
+ * @param {*Player} self - 自分（判定から除く）
+ * @returns {bool} - 使われていればtrue
+ */
+func colorTaken(gameRoom *GameRoom, color string, self *Player) bool {
+	for _, p := range gameRoom.Players {
+		if p != self && p.Color == color {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * プレイヤーに色を割り当てる
+ * 希望の色が使われていなければそれを、使われていれば既定の色のうち空いているものを選ぶ
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー
+ * @param {string} preferred - 希望の色（検証済み、空なら指定なし）
+ * @returns {string} - 割り当てた色
+ */
+func assignColor(gameRoom *GameRoom, p *Player, preferred string) string {
+	if preferred != "" && !colorTaken(gameRoom, preferred, p) {
+		return preferred
+	}
+	var free []string
+	for _, c := range playerColors {
+		if !colorTaken(gameRoom, c, p) {
+			free = append(free, c)
+		}
+	}
+	if len(free) == 0 {
+		return playerColors[rand.Intn(len(playerColors))]
+	}
+	return free[rand.Intn(len(free))]
+}
+
+/**
+ * プレイヤーの名前・色を変更し、ルームに通知する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {*Player} p - プレイヤー
+ * @param {string} name - 新しい名前（空なら変更しない）
+ * @param {string} color - 新しい色（空なら変更しない）
+ * @returns {error} - 検証エラー（あれば、何も変更しない）
+ */
+func changeProfile(gameRoom *GameRoom, p *Player, name, color string) error {
+	var err error
+	if name != "" {
+		if name, err = validateName(name); err != nil {
+			return err
+		}
+	}
+	if color != "" {
+		if color, err = validateColor(color); err != nil {
+			return err
+		}
+	}
+
+	gameRoom.Mutex.Lock()
+	if color != "" && gameRoom.versus != nil {
+		gameRoom.Mutex.Unlock()
+		return errColorTeam
+	}
+	if color != "" && colorTaken(gameRoom, color, p) {
+		gameRoom.Mutex.Unlock()
+		return errColorTaken
+	}
+	previous := p.Name
+	if name != "" {
+		p.Name = name
+	}
+	if color != "" {
+		p.Color = color
+	}
+	event := map[string]string{
+		"playerId":     p.ID,
+		"name":         p.Name,
+		"color":        p.Color,
+		"previousName": previous,
+	}
+	gameRoom.Mutex.Unlock()
+
+	broadcastMessage(gameRoom, Message{Type: "playerProfile", Data: event})
+	return nil
+}
@@ -0,0 +1,126 @@
+/**
+ * @file names_test.go
+ * @description プレイヤー名・色の検証と禁止語フィルタのテスト
+ *
+ * 概要:
+ * - 名前の整形と、空・長すぎる・制御文字・禁止語の拒否を確認する
+ * - 言い換え（記号・数字・大文字）をした禁止語も検出されることを確認する
+ * - 色の形式・明るさの検証と、ルーム内で色が重ならない割り当てを確認する
+ * - 名前・色の変更が検証に失敗したときは何も変更しないことを確認する
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"  Ace   Pilot ", "Ace Pilot", nil},
+		{"宇宙のエース", "宇宙のエース", nil},
+		{strings.Repeat("あ", maxDisplayNameLength), strings.Repeat("あ", maxDisplayNameLength), nil},
+		{"", "", errNameEmpty},
+		{" \t ", "", errNameEmpty},
+		{strings.Repeat("a", maxDisplayNameLength+1), "", errNameTooLong},
+		{"bad\u0007bell", "", errNameInvalid},
+		{"zero\u200bwidth", "", errNameInvalid},
+		{"ShitLord", "", errNameProfane},
+		{"5h1t", "", errNameProfane},
+		{"f.u.c.k", "", errNameProfane},
+		{"しね太郎", "", errNameProfane},
+	}
+	for _, tt := range tests {
+		got, err := validateName(tt.input)
+		if got != tt.want || err != tt.err {
+			t.Errorf("validateName(%q) = %q, %v, want %q, %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCensorBannedWords(t *testing.T) {
+	if got := censorBannedWords("nice  shot   you b1tch"); got != "nice shot you *****" {
+		t.Errorf("伏せ字 %q", got)
+	}
+	if got := censorBannedWords("good game"); got != "good game" {
+		t.Errorf("禁止語のない文が変わった: %q", got)
+	}
+}
+
+func TestValidateColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"#ff8800", "#FF8800", nil},
+		{"#3399FF", "#3399FF", nil},
+		{"ff8800", "", errColorInvalid},
+		{"#F80", "", errColorInvalid},
+		{"#GG0000", "", errColorInvalid},
+		{"#000000", "", errColorTooDark},
+		{"#202020", "", errColorTooDark},
+	}
+	for _, tt := range tests {
+		got, err := validateColor(tt.input)
+		if got != tt.want || err != tt.err {
+			t.Errorf("validateColor(%q) = %q, %v, want %q, %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+	for _, c := range playerColors {
+		if _, err := validateColor(c); err != nil {
+			t.Errorf("既定の色 %s が検証に通らない: %v", c, err)
+		}
+	}
+}
+
+func TestAssignColorAvoidsTaken(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	first := testPlayer(gameRoom, "p1", 100, 500)
+	first.Color = assignColor(gameRoom, first, "#FF8800")
+	if first.Color != "#FF8800" {
+		t.Fatalf("空いている希望の色が割り当てられない: %s", first.Color)
+	}
+
+	used := map[string]bool{first.Color: true}
+	for i := 0; i < len(playerColors)-2; i++ {
+		p := testPlayer(gameRoom, fmt.Sprintf("p%d", i+2), 100, 500)
+		p.Color = assignColor(gameRoom, p, "#FF8800")
+		if used[p.Color] {
+			t.Fatalf("%s に使用中の色 %s が割り当てられた", p.ID, p.Color)
+		}
+		used[p.Color] = true
+	}
+}
+
+func TestChangeProfileRejectsInvalid(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+	p.Color = "#FF0000"
+	other := testPlayer(gameRoom, "p2", 200, 500)
+	other.Color = "#00FF00"
+
+	// 名前が正しくても、色が使用中なら名前も変えない
+	if err := changeProfile(gameRoom, p, "New Name", "#00ff00"); err != errColorTaken {
+		t.Fatalf("使用中の色: %v, want %v", err, errColorTaken)
+	}
+	if err := changeProfile(gameRoom, p, "shithead", ""); err != errNameProfane {
+		t.Fatalf("禁止語の名前: %v, want %v", err, errNameProfane)
+	}
+	if p.Name != "p1" || p.Color != "#FF0000" {
+		t.Fatalf("検証に失敗した変更が反映された: %s %s", p.Name, p.Color)
+	}
+
+	if err := changeProfile(gameRoom, p, " New  Name ", "#ff8800"); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "New Name" || p.Color != "#FF8800" {
+		t.Errorf("変更後 %q %s", p.Name, p.Color)
+	}
+}
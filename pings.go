@@ -0,0 +1,214 @@
+/**
+ * @file pings.go
+ * @description 味方への合図（ピン）とマーカー
+ *
+ * 概要:
+ * - "ping" メッセージで、画面上の位置に一定時間表示されるマーカーを置く
+ * - 位置に敵・ボス・アイテムがあればそのエンティティに付け、移動に合わせて追従する
+ * - 合図の種類（ここ・攻撃・援護・危険・アイテム）を選べる。省略すると対象に合わせて決まる
+ * - プレイヤーごとに連続で置ける間隔と同時に置ける数を制限する（古いものから消える）
+ * - マーカーはゲーム状態に含めてルームの全員に送る（チーム対戦では置いたプレイヤーのチームにだけ送る）
+ */
+
+package main
+
+import "errors"
+
+// 合図の種類
+const (
+	calloutHere   = "here"   // ここ
+	calloutAttack = "attack" // 攻撃
+	calloutHelp   = "help"   // 援護
+	calloutDanger = "danger" // 危険
+	calloutItem   = "item"   // アイテム
+)
+
+// 選択できる合図の種類
+var callouts = map[string]bool{
+	calloutHere:   true,
+	calloutAttack: true,
+	calloutHelp:   true,
+	calloutDanger: true,
+	calloutItem:   true,
+}
+
+const (
+	// マーカーの表示時間（ティック）
+	pingLifetimeTicks = 180
+	// 次のピンを置けるまでの間隔（ティック）
+	pingCooldownTicks = 30
+	// プレイヤーごとに同時に置けるマーカーの数
+	maxPingsPerPlayer = 3
+	// 位置からエンティティを探すときの余白（ピクセル）
+	pingSnapMargin = 12
+)
+
+var (
+	errPingCooldown = errors.New("合図は少し間を空けて送ってください")
+	errPingCallout  = errors.New("不明な合図です")
+)
+
+/**
+ * マーカー構造体
+ * @property {int} ID - マーカーの番号（ルーム内で一意）
+ * @property {string} PlayerID - 置いたプレイヤーのID
+ * @property {string} Color - 置いたプレイヤーの色
+ * @property {string} Team - 置いたプレイヤーのチーム（チーム対戦以外は空、送信しない）
+ * @property {string} Callout - 合図の種類
+ * @property {string} TargetType - 付けた対象の種類（"enemy", "boss", "item"、位置だけなら空）
+ * @property {EntityID} Target - 付けた対象のハンドル（位置だけなら0）
+ * @property {float64} X - X座標（対象があれば対象の中心）
+ * @property {float64} Y - Y座標（対象があれば対象の中心）
+ * @property {int} Ticks - 残り表示時間
+ */
+type Ping struct {
+	ID         int      `json:"id"`
+	PlayerID   string   `json:"playerId"`
+	Color      string   `json:"color"`
+	Team       string   `json:"-"`
+	Callout    string   `json:"callout"`
+	TargetType string   `json:"targetType,omitempty"`
+	Target     EntityID `json:"target,omitempty"`
+	X          float64  `json:"x"`
+	Y          float64  `json:"y"`
+	Ticks      int      `json:"ticks"`
+}
+
+/**
+ * 位置にあるエンティティを探す（ボス、敵、アイテムの順）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @returns {*Entity} - 見つかったエンティティ（なければnil）
+ */
+func entityAt(gameRoom *GameRoom, x, y float64) *Entity {
+	for _, entities := range []map[EntityID]*Entity{gameRoom.Bosses, gameRoom.Enemies, gameRoom.Items} {
+		for _, e := range entities {
+			if x >= e.X-pingSnapMargin && x <= e.X+float64(e.Width)+pingSnapMargin &&
+				y >= e.Y-pingSnapMargin && y <= e.Y+float64(e.Height)+pingSnapMargin {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+/**
+ * マーカーを置く
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロックしていないこと）
+ * @param {*Player} p - 置いたプレイヤー
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @param {string} callout - 合図の種類（空なら対象に合わせて決める）
+ * @returns {error} - 置けなかった理由（あれば）
+ */
+func placePing(gameRoom *GameRoom, p *Player, x, y float64, callout string) error {
+	if callout != "" && !callouts[callout] {
+		return errPingCallout
+	}
+
+	gameRoom.Mutex.Lock()
+	defer gameRoom.Mutex.Unlock()
+
+	if p.pingCooldown > 0 {
+		return errPingCooldown
+	}
+	p.pingCooldown = pingCooldownTicks
+
+	ping := &Ping{
+		PlayerID: p.ID,
+		Color:    p.Color,
+		Team:     p.Team,
+		Callout:  callout,
+		X:        clampFloat(x, 0, worldWidth),
+		Y:        clampFloat(y, 0, worldHeight),
+		Ticks:    pingLifetimeTicks,
+	}
+	if target := entityAt(gameRoom, ping.X, ping.Y); target != nil {
+		ping.Target = target.ID
+		ping.TargetType = target.Type
+		followTarget(ping, target)
+	}
+	if ping.Callout == "" {
+		switch ping.TargetType {
+		case "enemy", "boss":
+			ping.Callout = calloutAttack
+		case "item":
+			ping.Callout = calloutItem
+		default:
+			ping.Callout = calloutHere
+		}
+	}
+
+	// 同時に置ける数を超えたら、そのプレイヤーの古いマーカーから消す
+	count := 0
+	for i := len(gameRoom.pings) - 1; i >= 0; i-- {
+		if gameRoom.pings[i].PlayerID != p.ID {
+			continue
+		}
+		count++
+		if count >= maxPingsPerPlayer {
+			gameRoom.pings = append(gameRoom.pings[:i], gameRoom.pings[i+1:]...)
+		}
+	}
+
+	gameRoom.nextPingID++
+	ping.ID = gameRoom.nextPingID
+	gameRoom.pings = append(gameRoom.pings, ping)
+	return nil
+}
+
+/**
+ * マーカーを対象の中心に移動する
+ * @param {*Ping} ping - マーカー
+ * @param {*Entity} target - 対象
+ */
+func followTarget(ping *Ping, target *Entity) {
+	ping.X = target.X + float64(target.Width)/2
+	ping.Y = target.Y + float64(target.Height)/2
+}
+
+/**
+ * ピンシステム: マーカーの表示時間を進め、対象に追従させる
+ * 表示時間が尽きたもの、対象がいなくなったものは消す
+ */
+func pingSystem(gameRoom *GameRoom) {
+	for _, p := range gameRoom.Players {
+		if p.pingCooldown > 0 {
+			p.pingCooldown--
+		}
+	}
+
+	live := gameRoom.pings[:0]
+	for _, ping := range gameRoom.pings {
+		ping.Ticks--
+		if ping.Ticks <= 0 {
+			continue
+		}
+		if ping.Target != 0 {
+			target := gameRoom.entities.Get(ping.Target)
+			if target == nil || target.removed {
+				continue
+			}
+			followTarget(ping, target)
+		}
+		live = append(live, ping)
+	}
+	gameRoom.pings = live
+}
+
+/**
+ * チームに見せるマーカーを取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {string} team - チーム名
+ * @returns {[]*Ping} - そのチームのプレイヤーが置いたマーカー（古い順）
+ */
+func teamPings(gameRoom *GameRoom, team string) []*Ping {
+	pings := []*Ping{}
+	for _, ping := range gameRoom.pings {
+		if ping.Team == team {
+			pings = append(pings, ping)
+		}
+	}
+	return pings
+}
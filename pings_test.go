@@ -0,0 +1,153 @@
+/**
+ * @file pings_test.go
+ * @description マーカーのテスト
+ *
+ * 概要:
+ * - チーム対戦では置いたプレイヤーのチームのマーカーだけを見せることを確認する
+ * - 連続で置ける間隔と、プレイヤーごとに同時に置ける数の制限を確認する
+ * - 敵に付けたマーカーが敵に追従し、敵が消えると消えることを確認する
+ * - マーカーが画面内に収められ、表示時間が過ぎると消えることを確認する
+ */
+
+package main
+
+import "testing"
+
+func TestTeamPings(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeVersus, RoomRules{MaxPlayers: versusPlayers, FirePower: 1})
+	var players []*Player
+	for _, id := range []string{"red1", "blue1", "red2"} {
+		p := &Player{Entity: Entity{Transform: transformAt(0, 0, 30, 30)}, ID: id}
+		joinTeam(gameRoom, p)
+		gameRoom.Players[p.ID] = p
+		players = append(players, p)
+	}
+	for _, p := range players {
+		if err := placePing(gameRoom, p, 100, 100, calloutHere); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		team string
+		want []string
+	}{
+		{teamRed, []string{"red1", "red2"}},
+		{teamBlue, []string{"blue1"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		pings := teamPings(gameRoom, tt.team)
+		if len(pings) != len(tt.want) {
+			t.Fatalf("%q のマーカーが %d 個、want %d 個", tt.team, len(pings), len(tt.want))
+		}
+		for i, ping := range pings {
+			if ping.PlayerID != tt.want[i] {
+				t.Errorf("%q の %d 個目のマーカーを置いたのが %s、want %s", tt.team, i, ping.PlayerID, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestPingCooldown(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+
+	if err := placePing(gameRoom, p, 100, 100, "shout"); err != errPingCallout {
+		t.Fatalf("不明な合図: %v, want %v", err, errPingCallout)
+	}
+	if err := placePing(gameRoom, p, 100, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < pingCooldownTicks-1; i++ {
+		pingSystem(gameRoom)
+	}
+	if err := placePing(gameRoom, p, 200, 100, ""); err != errPingCooldown {
+		t.Fatalf("間隔内のピン: %v, want %v", err, errPingCooldown)
+	}
+	pingSystem(gameRoom)
+	if err := placePing(gameRoom, p, 200, 100, ""); err != nil {
+		t.Fatalf("間隔を空けても置けない: %v", err)
+	}
+}
+
+func TestPingLimitPerPlayer(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+	other := testPlayer(gameRoom, "p2", 200, 500)
+	if err := placePing(gameRoom, other, 700, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxPingsPerPlayer+1; i++ {
+		p.pingCooldown = 0
+		if err := placePing(gameRoom, p, float64(i*100), 300, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var xs []float64
+	for _, ping := range gameRoom.pings {
+		if ping.PlayerID == p.ID {
+			xs = append(xs, ping.X)
+		}
+	}
+	if len(xs) != maxPingsPerPlayer || xs[0] != 100 {
+		t.Errorf("残ったマーカーの位置 %v, want 最も古い x=0 だけが消える", xs)
+	}
+	if len(gameRoom.pings) != maxPingsPerPlayer+1 {
+		t.Errorf("他のプレイヤーのマーカーが消えた: 全 %d 個", len(gameRoom.pings))
+	}
+}
+
+func TestPingFollowsTarget(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+	enemy := stillEnemy(gameRoom, "grunt", 300, 100)
+	enemy.VelocityY = 2
+
+	// 余白の内側なら敵に付き、合図は「攻撃」になる
+	if err := placePing(gameRoom, p, 300-pingSnapMargin+2, 105, ""); err != nil {
+		t.Fatal(err)
+	}
+	ping := gameRoom.pings[0]
+	if ping.Target != enemy.ID || ping.TargetType != "enemy" || ping.Callout != calloutAttack {
+		t.Fatalf("敵に付かない: %+v", ping)
+	}
+	for i := 0; i < 10; i++ {
+		movementSystem(gameRoom)
+		pingSystem(gameRoom)
+	}
+	if ping.X != enemy.X+15 || ping.Y != enemy.Y+15 {
+		t.Errorf("マーカー (%v, %v) が敵の中心 (%v, %v) に追従しない", ping.X, ping.Y, enemy.X+15, enemy.Y+15)
+	}
+
+	// 対象がいなくなるとマーカーも消える
+	despawn(gameRoom, enemy)
+	pingSystem(gameRoom)
+	if len(gameRoom.pings) != 0 {
+		t.Fatalf("対象が消えてもマーカーが残っている: %+v", gameRoom.pings)
+	}
+}
+
+func TestPingExpiresAndClamps(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+
+	if err := placePing(gameRoom, p, -50, worldHeight+50, calloutDanger); err != nil {
+		t.Fatal(err)
+	}
+	ping := gameRoom.pings[0]
+	if ping.X != 0 || ping.Y != worldHeight || ping.Callout != calloutDanger || ping.Target != 0 {
+		t.Fatalf("画面外の位置のマーカー %+v", ping)
+	}
+	for i := 0; i < pingLifetimeTicks-1; i++ {
+		pingSystem(gameRoom)
+	}
+	if len(gameRoom.pings) != 1 {
+		t.Fatal("表示時間より早くマーカーが消えた")
+	}
+	pingSystem(gameRoom)
+	if len(gameRoom.pings) != 0 {
+		t.Fatal("表示時間が過ぎてもマーカーが残っている")
+	}
+}
@@ -0,0 +1,221 @@
+/**
+ * @file pool.go
+ * @description エンティティのプールと世代付きハンドル
+ *
+ * 概要:
+ * - 弾・敵・ボス・アイテムはルームごとのプールのスロットを再利用し、毎回のヒープ確保を避ける
+ * - スロットはコンポーネントの実体も持ち、エンティティはそれを指す（追加の確保なし）
+ * - エンティティIDは「スロット番号＋世代」の数値ハンドル。スロットを再利用すると世代が進むため、
+ *   古いハンドルが別のエンティティを指すことはない
+ * - 取り除いたエンティティは同じティック内では再利用せず、ティックの最後にまとめて返却する
+ *   （衝突処理中のポインタが別のエンティティにすり替わらないようにするため）
+ * - UUIDはプレイヤーとルームにのみ使用する
+ */
+
+package main
+
+// エンティティハンドル（下位20ビット：スロット番号、上位12ビット：世代。0は無効）
+type EntityID uint32
+
+const (
+	// スロット番号のビット数
+	entityIndexBits = 20
+	// スロット番号の最大値
+	entityIndexMask = 1<<entityIndexBits - 1
+	// 世代の最大値
+	entityGenerationMask = 1<<(32-entityIndexBits) - 1
+	// 一度に確保するスロット数（確保済みのスロットは移動しないのでポインタが安定する）
+	entityChunkSize = 256
+)
+
+/**
+ * ハンドルのスロット番号
+ * @returns {uint32} - スロット番号
+ */
+func (id EntityID) index() uint32 {
+	return uint32(id) & entityIndexMask
+}
+
+/**
+ * ハンドルの世代
+ * @returns {uint32} - 世代
+ */
+func (id EntityID) generation() uint32 {
+	return uint32(id) >> entityIndexBits
+}
+
+/**
+ * コンポーネントの実体（プールのスロットごとに1つ）
+ * 各メソッドは値を実体にコピーし、エンティティに設定するポインタを返す
+ */
+type ComponentStore struct {
+	velocity Velocity
+	collider Collider
+	health   Health
+	ai       AI
+	lifetime Lifetime
+	pickup   Pickup
+}
+
+// 速度コンポーネントを設定する
+func (c *ComponentStore) Velocity(v Velocity) *Velocity {
+	c.velocity = v
+	return &c.velocity
+}
+
+// 衝突コンポーネントを設定する（命中記録のスライスは容量を再利用する）
+func (c *ComponentStore) Collider(v Collider) *Collider {
+	hits, hitPlayers := c.collider.hits[:0], c.collider.hitPlayers[:0]
+	c.collider = v
+	c.collider.hits, c.collider.hitPlayers = hits, hitPlayers
+	return &c.collider
+}
+
+// 体力コンポーネントを設定する（攻撃者記録・クールダウンのスライスは容量を再利用する）
+func (c *ComponentStore) Health(v Health) *Health {
+	attackers, cooldowns := c.health.attackers[:0], c.health.cooldowns[:0]
+	c.health = v
+	c.health.attackers, c.health.cooldowns = attackers, cooldowns
+	return &c.health
+}
+
+// AIコンポーネントを設定する
+func (c *ComponentStore) AI(v AI) *AI {
+	c.ai = v
+	return &c.ai
+}
+
+// 寿命コンポーネントを設定する
+func (c *ComponentStore) Lifetime(v Lifetime) *Lifetime {
+	c.lifetime = v
+	return &c.lifetime
+}
+
+// アイテムコンポーネントを設定する
+func (c *ComponentStore) Pickup(v Pickup) *Pickup {
+	c.pickup = v
+	return &c.pickup
+}
+
+/**
+ * プールのスロット
+ * @property {Entity} entity - エンティティ本体
+ * @property {ComponentStore} components - コンポーネントの実体
+ * @property {uint32} generation - 世代（スロットを返却するたびに進む）
+ * @property {bool} alive - 使用中かどうか
+ */
+type entitySlot struct {
+	entity     Entity
+	components ComponentStore
+	generation uint32
+	alive      bool
+}
+
+/**
+ * エンティティプール構造体
+ * @property {[][]entitySlot} chunks - スロットの塊（一度確保した塊は移動しない）
+ * @property {int} size - 使用したことのあるスロット数
+ * @property {[]uint32} free - 再利用できるスロット番号
+ * @property {[]*Entity} pending - ティックの最後に返却するエンティティ
+ * @property {uint64} spawned - これまでに生成した数（生成順の通し番号に使う）
+ */
+type EntityPool struct {
+	chunks  [][]entitySlot
+	size    int
+	free    []uint32
+	pending []*Entity
+	spawned uint64
+}
+
+/**
+ * 新しいエンティティプールを作成する
+ * @returns {*EntityPool} - 作成されたプール
+ */
+func newEntityPool() *EntityPool {
+	return &EntityPool{}
+}
+
+/**
+ * スロット番号からスロットを取得する
+ * @param {uint32} index - スロット番号
+ * @returns {*entitySlot} - スロット
+ */
+func (p *EntityPool) slot(index uint32) *entitySlot {
+	return &p.chunks[index/entityChunkSize][index%entityChunkSize]
+}
+
+/**
+ * エンティティを生成する
+ * 空きスロットを再利用し、なければ新しいスロットを使う
+ * @returns {*Entity, *ComponentStore} - 新しいハンドルが設定された空のエンティティと、そのコンポーネントの実体
+ */
+func (p *EntityPool) Spawn() (*Entity, *ComponentStore) {
+	var index uint32
+	if n := len(p.free); n > 0 {
+		index = p.free[n-1]
+		p.free = p.free[:n-1]
+	} else {
+		if p.size > entityIndexMask {
+			panic("エンティティプールの上限を超えました")
+		}
+		index = uint32(p.size)
+		if index%entityChunkSize == 0 {
+			p.chunks = append(p.chunks, make([]entitySlot, entityChunkSize))
+		}
+		p.size++
+	}
+
+	s := p.slot(index)
+	if s.generation == 0 {
+		s.generation = 1
+	}
+	s.alive = true
+	p.spawned++
+	s.entity = Entity{ID: EntityID(s.generation<<entityIndexBits | index), seq: p.spawned}
+	return &s.entity, &s.components
+}
+
+/**
+ * ハンドルからエンティティを取得する
+ * @param {EntityID} id - ハンドル
+ * @returns {*Entity} - エンティティ（返却済み・世代違いならnil）
+ */
+func (p *EntityPool) Get(id EntityID) *Entity {
+	index := id.index()
+	if id == 0 || int(index) >= p.size {
+		return nil
+	}
+	s := p.slot(index)
+	if !s.alive || s.generation != id.generation() {
+		return nil
+	}
+	return &s.entity
+}
+
+/**
+ * エンティティを返却予定にする
+ * 削除済みの印を付け、実際の返却は Flush で行う
+ * @param {*Entity} e - エンティティ（プール外のエンティティは無視する）
+ */
+func (p *EntityPool) Release(e *Entity) {
+	if e.removed || p.Get(e.ID) != e {
+		return
+	}
+	e.removed = true
+	p.pending = append(p.pending, e)
+}
+
+/**
+ * 返却予定のエンティティのスロットを空きに戻す（ティックの最後に呼ぶ）
+ */
+func (p *EntityPool) Flush() {
+	for _, e := range p.pending {
+		index := e.ID.index()
+		s := p.slot(index)
+		s.alive = false
+		s.generation = (s.generation + 1) & entityGenerationMask
+		s.entity = Entity{}
+		p.free = append(p.free, index)
+	}
+	p.pending = p.pending[:0]
+}
@@ -0,0 +1,153 @@
+/**
+ * @file pool_test.go
+ * @description エンティティプールのテストとベンチマーク
+ *
+ * 概要:
+ * - 返却したハンドルが無効になり、再利用したスロットの世代が進むことを確認する
+ * - 生成・返却と状態の送信について、プールのハンドルと従来の &Entity{}＋UUID の確保を比較する
+ *   （go test -bench . -run ^$ で実行）
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// ベンチマークで1ティックに生成・返却する弾の数
+const benchEntities = 500
+
+func TestEntityPoolStaleHandle(t *testing.T) {
+	pool := newEntityPool()
+	e, _ := pool.Spawn()
+	id := e.ID
+	if pool.Get(id) != e {
+		t.Fatal("生成直後のハンドルでエンティティを取得できない")
+	}
+
+	// 返却予定のうちは同じティック内の処理から参照できる
+	pool.Release(e)
+	if pool.Get(id) != e {
+		t.Fatal("Flush 前にハンドルが無効になった")
+	}
+	pool.Flush()
+	if pool.Get(id) != nil {
+		t.Fatal("Flush 後の古いハンドルでエンティティを取得できた")
+	}
+
+	// 同じスロットが再利用され、世代が進む
+	reused, _ := pool.Spawn()
+	if reused.ID.index() != id.index() {
+		t.Fatalf("スロットが再利用されていない: %d != %d", reused.ID.index(), id.index())
+	}
+	if reused.ID.generation() == id.generation() {
+		t.Fatalf("再利用したスロットの世代が進んでいない: %d", reused.ID.generation())
+	}
+	if pool.Get(id) != nil {
+		t.Fatal("古いハンドルが再利用したエンティティを指している")
+	}
+	if pool.Get(reused.ID) != reused {
+		t.Fatal("新しいハンドルでエンティティを取得できない")
+	}
+}
+
+func TestEntityPoolReleaseTwice(t *testing.T) {
+	pool := newEntityPool()
+	e, _ := pool.Spawn()
+	pool.Release(e)
+	pool.Release(e)
+	pool.Flush()
+	if len(pool.free) != 1 {
+		t.Fatalf("二重に返却された: 空きスロット %d", len(pool.free))
+	}
+}
+
+/**
+ * 従来の方法で弾を生成する（ヒープに確保し、UUIDで管理する）
+ * @param {map[string]*Entity} bullets - 弾のマップ（キー：UUID）
+ */
+func spawnHeapBullet(bullets map[string]*Entity) {
+	e := &Entity{
+		Type:      "bullet",
+		Transform: transformAt(300, 500, 5, 10),
+		Velocity:  &Velocity{VelocityY: -10},
+		Collider:  &Collider{Damage: 1},
+		Lifetime:  &Lifetime{},
+	}
+	bullets[uuid.New().String()] = e
+}
+
+/**
+ * プールから弾を生成する
+ * @param {*EntityPool} pool - エンティティプール
+ * @param {map[EntityID]*Entity} bullets - 弾のマップ（キー：ハンドル）
+ */
+func spawnPooledBullet(pool *EntityPool, bullets map[EntityID]*Entity) {
+	e, c := pool.Spawn()
+	e.Type = "bullet"
+	e.Transform = transformAt(300, 500, 5, 10)
+	e.Velocity = c.Velocity(Velocity{VelocityY: -10})
+	e.Collider = c.Collider(Collider{Damage: 1})
+	e.Lifetime = c.Lifetime(Lifetime{})
+	bullets[e.ID] = e
+}
+
+func BenchmarkSpawnRelease(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		pool := newEntityPool()
+		bullets := make(map[EntityID]*Entity)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < benchEntities; j++ {
+				spawnPooledBullet(pool, bullets)
+			}
+			for id, e := range bullets {
+				pool.Release(e)
+				delete(bullets, id)
+			}
+			pool.Flush()
+		}
+	})
+	b.Run("heap", func(b *testing.B) {
+		b.ReportAllocs()
+		bullets := make(map[string]*Entity)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < benchEntities; j++ {
+				spawnHeapBullet(bullets)
+			}
+			for id := range bullets {
+				delete(bullets, id)
+			}
+		}
+	})
+}
+
+func BenchmarkBroadcastState(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+		for j := 0; j < benchEntities; j++ {
+			spawnPooledBullet(gameRoom.entities, gameRoom.Bullets)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			broadcastGameState(gameRoom) // 接続中のクライアントがいないのでエンコードのみ
+		}
+	})
+	b.Run("heap", func(b *testing.B) {
+		bullets := make(map[string]*Entity)
+		for j := 0; j < benchEntities; j++ {
+			spawnHeapBullet(bullets)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(Message{Type: "gameState", Data: map[string]interface{}{"bullets": bullets}}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,174 @@
+/**
+ * @file respawn.go
+ * @description プレイヤーの死亡・リスポーン・蘇生
+ *
+ * 概要:
+ * - 体力が0になったプレイヤーはその場に倒れ、移動・射撃・衝突の対象から外れる
+ * - 残機があれば消費し、一定時間後に画面下部でリスポーンする（ルールで共有するときはルームの残機を消費する）
+ * - 残機がなくても、生存中の味方が近くに一定時間留まれば蘇生できる
+ * - リスポーン・蘇生の直後は一定時間無敵になる
+ * - 全員が倒れ、リスポーン待ちのプレイヤーもいなければゲームオーバー
+ * - デスマッチ・チーム対戦では残機を消費せず必ずリスポーンし、蘇生・ゲームオーバーはない
+ */
+
+package main
+
+const (
+	// 参加時・リスタート時の残機
+	startingLives = 2
+	// 倒れてからリスポーンするまでのティック数
+	respawnDelayTicks = 180
+	// 残機を消費しないモードでリスポーンするまでのティック数
+	freeRespawnDelayTicks = 120
+	// リスポーン・蘇生直後の無敵ティック数
+	spawnInvulnerabilityTicks = 120
+	// 蘇生できる距離（機体の中心同士）
+	reviveRadius = 60.0
+	// 蘇生に必要なティック数（味方が離れると進行が戻る）
+	reviveTicks = 120
+	// 蘇生時の体力の割合
+	reviveHealthRatio = 0.5
+)
+
+/**
+ * プレイヤーが生存しているか
+ * @param {*Player} p - プレイヤー
+ * @returns {bool} - 体力が残っていればtrue
+ */
+func isAlive(p *Player) bool {
+	return p.Health.Current > 0
+}
+
+/**
+ * プレイヤーを倒れた状態にする
+ * 衝突レイヤーを外して当たり判定をなくし、残機があればリスポーンを予約する。チーム対戦ではチームの体力も減る
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー
+ */
+func killPlayer(gameRoom *GameRoom, p *Player) {
+	p.Health.Current = 0
+	p.VelocityX, p.VelocityY = 0, 0
+	p.Layer, p.Mask = 0, 0
+	p.Combo, p.comboTimer = 0, 0
+	p.Effects = make(map[string]int)
+	p.ReviveProgress = 0
+	p.KnockbackX, p.KnockbackY = 0, 0
+
+	if t := playerTeam(gameRoom, p); t != nil {
+		damageTeam(t, teamDownPenalty)
+	}
+	if roomMode(gameRoom).FreeRespawn {
+		p.RespawnTicks = freeRespawnDelayTicks
+	} else if lives := livesOf(gameRoom, p); *lives > 0 {
+		*lives--
+		p.RespawnTicks = respawnDelayTicks
+	}
+}
+
+/**
+ * プレイヤーを復帰させる
+ * @param {*Player} p - プレイヤー
+ * @param {int} health - 復帰時の体力
+ */
+func revivePlayer(p *Player, health int) {
+	p.Health.Current = health
+	p.RespawnTicks = 0
+	p.ReviveProgress = 0
+	p.Invulnerable = spawnInvulnerabilityTicks
+	setLayer(&p.Entity, layerPlayer)
+}
+
+/**
+ * プレイヤーを画面下部の出現位置に移動する
+ * @param {*Player} p - プレイヤー
+ */
+func moveToSpawn(p *Player) {
+	p.X = worldWidth/2 - float64(p.Width)/2
+	p.Y = worldHeight - float64(p.Height) - 60
+	p.prevX, p.prevY = p.X, p.Y
+}
+
+/**
+ * 全員が倒れ、リスポーン待ちのプレイヤーもいなければゲームオーバーにする
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func checkGameOver(gameRoom *GameRoom) {
+	if len(gameRoom.Players) == 0 || roomMode(gameRoom).FreeRespawn {
+		return
+	}
+	for _, p := range gameRoom.Players {
+		if isAlive(p) || p.RespawnTicks > 0 {
+			return
+		}
+	}
+	gameRoom.GameState = "gameover"
+	finishStage(gameRoom, false)
+	submitScores(gameRoom)
+	recordProfileStats(gameRoom)
+}
+
+/**
+ * リスポーンシステム: リスポーン待ち・蘇生の進行を処理する
+ */
+func respawnSystem(gameRoom *GameRoom) {
+	mode := roomMode(gameRoom)
+	for _, p := range gameRoom.Players {
+		if isAlive(p) {
+			continue
+		}
+
+		// 倒れている間は動かない
+		p.VelocityX, p.VelocityY = 0, 0
+
+		if p.RespawnTicks > 0 {
+			p.RespawnTicks--
+			if p.RespawnTicks == 0 {
+				switch {
+				case mode.Teams:
+					moveToTeamSpawn(p)
+				case mode.PvP:
+					moveToArenaSpawn(gameRoom, p)
+				default:
+					moveToSpawn(p)
+				}
+				revivePlayer(p, p.Health.Max)
+				continue
+			}
+		}
+		if mode.FreeRespawn {
+			continue
+		}
+
+		// 生存中の味方が近くにいれば蘇生が進み、いなければ戻る
+		if reviverNearby(gameRoom, p) {
+			p.ReviveProgress++
+			if p.ReviveProgress >= reviveTicks {
+				revivePlayer(p, int(float64(p.Health.Max)*reviveHealthRatio))
+			}
+		} else if p.ReviveProgress > 0 {
+			p.ReviveProgress--
+		}
+	}
+	checkGameOver(gameRoom)
+}
+
+/**
+ * 倒れたプレイヤーの近くに生存中の味方がいるか
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} downed - 倒れたプレイヤー
+ * @returns {bool} - 蘇生できる距離に味方がいればtrue
+ */
+func reviverNearby(gameRoom *GameRoom, downed *Player) bool {
+	cx, cy := downed.X+float64(downed.Width)/2, downed.Y+float64(downed.Height)/2
+	for _, q := range gameRoom.Players {
+		if q == downed || !isAlive(q) {
+			continue
+		}
+		dx := q.X + float64(q.Width)/2 - cx
+		dy := q.Y + float64(q.Height)/2 - cy
+		if dx*dx+dy*dy <= reviveRadius*reviveRadius {
+			return true
+		}
+	}
+	return false
+}
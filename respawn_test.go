@@ -0,0 +1,129 @@
+/**
+ * @file respawn_test.go
+ * @description プレイヤーの死亡・リスポーン・蘇生のテスト
+ *
+ * 概要:
+ * - 倒れたプレイヤーが残機を1つ消費し、規定のティック数後に無敵状態でリスポーンすることを確認する
+ * - 残機がなくても、味方が近くに留まれば体力半分で蘇生し、離れると進行が戻ることを確認する
+ * - 全員が倒れ、リスポーン待ちもいなくなったときだけゲームオーバーになることを確認する
+ * - デスマッチでは残機を消費せずにリスポーンすることを確認する
+ */
+
+package main
+
+import "testing"
+
+func TestRespawnAfterDelay(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 100)
+
+	killPlayer(gameRoom, p)
+	if p.Lives != startingLives-1 || p.RespawnTicks != respawnDelayTicks || p.Layer != 0 {
+		t.Fatalf("倒れた直後: 残機 %d・待ち %d・レイヤー %b", p.Lives, p.RespawnTicks, p.Layer)
+	}
+	for i := 0; i < respawnDelayTicks-1; i++ {
+		respawnSystem(gameRoom)
+	}
+	if isAlive(p) || gameRoom.GameState != "playing" {
+		t.Fatalf("規定時間より早くリスポーンした、またはゲームオーバーになった: %s", gameRoom.GameState)
+	}
+	respawnSystem(gameRoom)
+
+	if !isAlive(p) || p.Health.Current != p.Health.Max {
+		t.Fatalf("リスポーンしない: 体力 %d", p.Health.Current)
+	}
+	if p.Invulnerable != spawnInvulnerabilityTicks || p.Layer != layerPlayer {
+		t.Errorf("リスポーン直後: 無敵 %d・レイヤー %b", p.Invulnerable, p.Layer)
+	}
+	if p.X != worldWidth/2-15 || p.Y != worldHeight-30-60 {
+		t.Errorf("出現位置 (%v, %v)", p.X, p.Y)
+	}
+
+	// 無敵の間は敵弾を受けない
+	if damagePlayer(gameRoom, p, &Entity{Collider: &Collider{Layer: layerEnemyShot, Damage: 15}}) {
+		t.Error("リスポーン直後の無敵中にダメージを受けた")
+	}
+}
+
+func TestReviveByAlly(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	downed := testPlayer(gameRoom, "downed", 100, 100)
+	ally := testPlayer(gameRoom, "ally", 100+reviveRadius-10, 100)
+	downed.Lives = 0
+	killPlayer(gameRoom, downed)
+	if downed.RespawnTicks != 0 {
+		t.Fatalf("残機がないのにリスポーン待ち %d", downed.RespawnTicks)
+	}
+
+	for i := 0; i < reviveTicks/2; i++ {
+		respawnSystem(gameRoom)
+	}
+	// 味方が離れると進行が戻る
+	ally.X = 100 + reviveRadius + 10
+	for i := 0; i < 10; i++ {
+		respawnSystem(gameRoom)
+	}
+	if want := reviveTicks/2 - 10; downed.ReviveProgress != want {
+		t.Fatalf("離れた後の進行 %d, want %d", downed.ReviveProgress, want)
+	}
+
+	ally.X = 100
+	for i := 0; i < reviveTicks/2+9; i++ {
+		respawnSystem(gameRoom)
+	}
+	if isAlive(downed) {
+		t.Fatal("必要な時間より早く蘇生した")
+	}
+	respawnSystem(gameRoom)
+	if want := int(maxPlayerHealth * reviveHealthRatio); downed.Health.Current != want {
+		t.Fatalf("蘇生後の体力 %d, want %d", downed.Health.Current, want)
+	}
+	if downed.Invulnerable != spawnInvulnerabilityTicks || downed.X != 100 {
+		t.Errorf("蘇生直後: 無敵 %d・位置 x=%v（その場で起き上がるはず）", downed.Invulnerable, downed.X)
+	}
+}
+
+func TestGameOverWhenEveryoneIsOut(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	first := testPlayer(gameRoom, "p1", 100, 100)
+	second := testPlayer(gameRoom, "p2", 700, 100)
+	first.Lives, second.Lives = 0, 1
+
+	killPlayer(gameRoom, first)
+	killPlayer(gameRoom, second)
+	respawnSystem(gameRoom)
+	if gameRoom.GameState != "playing" {
+		t.Fatal("リスポーン待ちがいるのにゲームオーバーになった")
+	}
+
+	// リスポーンした後、再び倒れて誰も残っていなければゲームオーバー
+	for i := 0; i < respawnDelayTicks; i++ {
+		respawnSystem(gameRoom)
+	}
+	if !isAlive(second) {
+		t.Fatal("残機のあるプレイヤーがリスポーンしない")
+	}
+	killPlayer(gameRoom, second)
+	respawnSystem(gameRoom)
+	if gameRoom.GameState != "gameover" {
+		t.Fatalf("全員が倒れてもゲームオーバーにならない: %s", gameRoom.GameState)
+	}
+}
+
+func TestFreeRespawnKeepsLives(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeDeathmatch, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 100)
+	testPlayer(gameRoom, "p2", 700, 500)
+	p.Lives = 0
+
+	killPlayer(gameRoom, p)
+	if p.RespawnTicks != freeRespawnDelayTicks {
+		t.Fatalf("リスポーン待ち %d, want %d", p.RespawnTicks, freeRespawnDelayTicks)
+	}
+	for i := 0; i < freeRespawnDelayTicks; i++ {
+		respawnSystem(gameRoom)
+	}
+	if !isAlive(p) || p.Lives != 0 {
+		t.Errorf("デスマッチのリスポーン: 生存 %v・残機 %d", isAlive(p), p.Lives)
+	}
+}
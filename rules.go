@@ -0,0 +1,287 @@
+/**
+ * @file rules.go
+ * @description ルームごとのゲームルールとルーム一覧
+ *
+ * 概要:
+ * - ルーム作成時にクエリパラメータでルールを指定する（フレンドリーファイア・残機の共有・アイテムの共有・最大人数・ボス出現に必要な撃破数・初期の武器レベル）
+ * - ルールはサーバー側で検証し、同じモード・難易度・ルールのルームにだけ参加する
+ * - 既定と異なるルールのルームの記録はリーダーボードに登録しない
+ * - GET /api/rooms でルームの一覧（ルールを含む）を返す
+ */
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// 最大人数の既定値と上限（上限はプレイヤーの色の数）
+	defaultMaxPlayers = 4
+	maxRoomPlayers    = 8
+	// チーム対戦の人数（2対2）
+	versusPlayers = 4
+	// PvPのモードの最少人数
+	minPvPPlayers = 2
+	// ボス出現に必要な撃破数の上限
+	maxBossThreshold = 100
+)
+
+// ルールの検証エラー
+var (
+	errRulesInvalid       = errors.New("ルールの指定が正しくありません")
+	errRulesMaxPlayers    = errors.New("最大人数は1〜8で指定してください")
+	errRulesVersusPlayers = errors.New("チーム対戦の最大人数は4人（2対2）です")
+	errRulesPvPPlayers    = errors.New("対戦モードの最大人数は2〜8で指定してください")
+	errRulesBossThreshold = errors.New("ボス出現に必要な撃破数は1〜100で指定してください")
+	errRulesFirePower     = errors.New("初期の武器レベルは1〜5で指定してください")
+	errRulesFriendlyFire  = errors.New("このモードではフレンドリーファイアを変更できません")
+	errRulesSharedLives   = errors.New("このモードでは残機を共有できません")
+	errRulesNoBoss        = errors.New("このモードにはボスが出現しません")
+	errRulesDaily         = errors.New("デイリーチャレンジではルールを変更できません")
+	errRoomUnavailable    = errors.New("指定したルームに参加できませんでした")
+)
+
+/**
+ * ルームのルール構造体
+ * @property {bool} FriendlyFire - trueならプレイヤーの弾が味方にも当たる（協力系のモードのみ）
+ * @property {bool} SharedLives - trueなら残機をルーム全体で共有する
+ * @property {bool} ItemSharing - trueなら取得したアイテムの効果が生存中の味方全員に及ぶ
+ * @property {int} MaxPlayers - 最大人数
+ * @property {int} BossThreshold - ボス出現に必要な撃破数（0ならレベルの既定値）
+ * @property {int} FirePower - 参加時・リスタート時の武器レベル
+ */
+type RoomRules struct {
+	FriendlyFire  bool `json:"friendlyFire"`
+	SharedLives   bool `json:"sharedLives"`
+	ItemSharing   bool `json:"itemSharing"`
+	MaxPlayers    int  `json:"maxPlayers"`
+	BossThreshold int  `json:"bossThreshold"`
+	FirePower     int  `json:"firePower"`
+}
+
+// 既定のルール
+var defaultRules = RoomRules{MaxPlayers: defaultMaxPlayers, FirePower: 1}
+
+/**
+ * ルーム一覧の項目構造体
+ * @property {string} ID - ルームID
+ * @property {string} Mode - ゲームモード
+ * @property {string} Difficulty - 難易度
+ * @property {string} Day - デイリーチャレンジの日付（他のモードは空）
+ * @property {string} GameState - ゲームの状態
+ * @property {int} Players - 参加中の人数
+ * @property {RoomRules} Rules - ルール
+ */
+type RoomInfo struct {
+	ID         string    `json:"id"`
+	Mode       string    `json:"mode"`
+	Difficulty string    `json:"difficulty"`
+	Day        string    `json:"day,omitempty"`
+	GameState  string    `json:"gameState"`
+	Players    int       `json:"players"`
+	Rules      RoomRules `json:"rules"`
+}
+
+/**
+ * クエリパラメータからルールを読み取って検証する
+ * 指定のない項目は既定値になる
+ * @param {echo.Context} c - Echoコンテキスト
+ * @param {string} mode - ゲームモード（正規化済み）
+ * @returns {RoomRules, error} - ルールと検証エラー
+ */
+func parseRules(c echo.Context, mode string) (RoomRules, error) {
+	rules := defaultRules
+	flags := map[string]*bool{
+		"friendlyFire": &rules.FriendlyFire,
+		"sharedLives":  &rules.SharedLives,
+		"itemSharing":  &rules.ItemSharing,
+	}
+	for name, flag := range flags {
+		if s := c.QueryParam(name); s != "" {
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return rules, errRulesInvalid
+			}
+			*flag = v
+		}
+	}
+	numbers := map[string]*int{
+		"maxPlayers":    &rules.MaxPlayers,
+		"bossThreshold": &rules.BossThreshold,
+		"firePower":     &rules.FirePower,
+	}
+	for name, number := range numbers {
+		if s := c.QueryParam(name); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return rules, errRulesInvalid
+			}
+			*number = v
+		}
+	}
+	return rules, validateRules(rules, mode)
+}
+
+/**
+ * ルールを検証する
+ * @param {RoomRules} rules - ルール
+ * @param {string} mode - ゲームモード（正規化済み）
+ * @returns {error} - 検証エラー（あれば）
+ */
+func validateRules(rules RoomRules, mode string) error {
+	m := gameModes[mode]
+	switch {
+	case rules.MaxPlayers < 1 || rules.MaxPlayers > maxRoomPlayers:
+		return errRulesMaxPlayers
+	case rules.BossThreshold < 0 || rules.BossThreshold > maxBossThreshold:
+		return errRulesBossThreshold
+	case rules.FirePower < 1 || rules.FirePower > maxFirePower:
+		return errRulesFirePower
+	case m.Daily && rules != defaultRules:
+		return errRulesDaily // 全員が同じ条件で競う
+	case m.Teams && rules.MaxPlayers != versusPlayers:
+		return errRulesVersusPlayers // チームの人数・陣地の広さは2対2が前提
+	case m.PvP && rules.MaxPlayers < minPvPPlayers:
+		return errRulesPvPPlayers
+	case rules.FriendlyFire && (m.PvP || m.Teams):
+		return errRulesFriendlyFire
+	case rules.SharedLives && m.FreeRespawn:
+		return errRulesSharedLives
+	case rules.BossThreshold > 0 && !m.Bosses:
+		return errRulesNoBoss
+	}
+	return nil
+}
+
+/**
+ * プレイヤーが消費する残機を取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー
+ * @returns {*int} - 残機（共有ならルームの残機、そうでなければプレイヤーの残機）
+ */
+func livesOf(gameRoom *GameRoom, p *Player) *int {
+	if gameRoom.Rules.SharedLives {
+		return &gameRoom.sharedLives
+	}
+	return &p.Lives
+}
+
+/**
+ * 残機の上限を取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {int} - 残機の上限（共有なら最大人数分）
+ */
+func livesLimit(gameRoom *GameRoom) int {
+	if gameRoom.Rules.SharedLives {
+		return maxLives * gameRoom.Rules.MaxPlayers
+	}
+	return maxLives
+}
+
+/**
+ * 参加したプレイヤーの分の残機を共有の残機に加える（残機を共有するルールのみ）
+ * 同じゲーム中に一度加えたプレイヤーは、抜けて入り直しても加えない。上限は livesLimit
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - 参加したプレイヤー
+ */
+func addSharedLives(gameRoom *GameRoom, p *Player) {
+	if !gameRoom.Rules.SharedLives || gameRoom.livesShared[p.identity] {
+		return
+	}
+	gameRoom.livesShared[p.identity] = true
+	gameRoom.sharedLives = min(gameRoom.sharedLives+startingLives, livesLimit(gameRoom))
+}
+
+/**
+ * リスタート時に共有の残機を参加中のプレイヤーの分に戻す
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func resetSharedLives(gameRoom *GameRoom) {
+	gameRoom.sharedLives = 0
+	gameRoom.livesShared = make(map[string]bool)
+	for _, p := range gameRoom.Players {
+		addSharedLives(gameRoom, p)
+	}
+}
+
+/**
+ * 取得したアイテムの効果を受けるプレイヤーを列挙する
+ * アイテムの共有がなければ取得したプレイヤーのみ。ボムと共有の残機は1回だけ効果がある
+ * チーム対戦では同じチームの味方のみ
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - アイテムを取得したプレイヤー
+ * @param {string} kind - アイテム種類
+ * @returns {[]*Player} - 効果を受けるプレイヤー
+ */
+func itemRecipients(gameRoom *GameRoom, p *Player, kind string) []*Player {
+	if !gameRoom.Rules.ItemSharing || kind == itemBomb || kind == itemLife && gameRoom.Rules.SharedLives {
+		return []*Player{p}
+	}
+	team := playerTeam(gameRoom, p)
+	var recipients []*Player
+	for _, other := range gameRoom.Players {
+		if other == p || isAlive(other) && playerTeam(gameRoom, other) == team {
+			recipients = append(recipients, other)
+		}
+	}
+	return recipients
+}
+
+/**
+ * ルームに参加できるか
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - 参加するプレイヤー
+ * @returns {bool} - 空きがあり、プレイ中で、キックされておらず、前日以前のデイリーチャレンジでなければtrue
+ */
+func canJoin(gameRoom *GameRoom, p *Player) bool {
+	if gameRoom.Day != "" && gameRoom.Day != challengeDay(time.Now()) {
+		return false
+	}
+	return len(gameRoom.Players) < gameRoom.Rules.MaxPlayers && gameRoom.GameState == "playing" && !gameRoom.banned[p.identity]
+}
+
+/**
+ * ルーム一覧取得ハンドラー
+ * GET /api/rooms?mode=coop（modeを省略すると全モード）
+ * @param {echo.Context} c - Echoコンテキスト
+ * @returns {error} - エラー（あれば）
+ */
+func handleRooms(c echo.Context) error {
+	mode := c.QueryParam("mode")
+	if _, ok := gameModes[mode]; mode != "" && !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "不明なモードです")
+	}
+
+	gamesMutex.Lock()
+	rooms := []RoomInfo{}
+	for _, room := range gameRooms {
+		room.Mutex.Lock()
+		if mode == "" || room.Mode == mode {
+			rooms = append(rooms, RoomInfo{
+				ID:         room.ID,
+				Mode:       room.Mode,
+				Difficulty: room.Difficulty,
+				Day:        room.Day,
+				GameState:  room.GameState,
+				Players:    len(room.Players),
+				Rules:      room.Rules,
+			})
+		}
+		room.Mutex.Unlock()
+	}
+	gamesMutex.Unlock()
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].ID < rooms[j].ID
+	})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rooms": rooms,
+	})
+}
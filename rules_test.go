@@ -0,0 +1,124 @@
+/**
+ * @file rules_test.go
+ * @description ルームのルールのテスト
+ *
+ * 概要:
+ * - 共有の残機は同じゲーム中にプレイヤーごとに一度だけ加わり、上限を超えないことを確認する
+ * - 範囲外の値やモードと組み合わせられないルールが拒否されることを確認する
+ * - クエリパラメータからルールを読み取り、形式の誤りを拒否することを確認する
+ */
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestSharedLivesRejoin(t *testing.T) {
+	rules := defaultRules
+	rules.SharedLives = true
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, rules)
+
+	join := func(id, identity string) *Player {
+		p := testPlayer(gameRoom, id, 100, 500)
+		p.identity = identity
+		addSharedLives(gameRoom, p)
+		return p
+	}
+	join("a", "guest:a")
+	b := join("b", "guest:b")
+	if want := 2 * startingLives; gameRoom.sharedLives != want {
+		t.Fatalf("2人参加で共有の残機 %d, want %d", gameRoom.sharedLives, want)
+	}
+
+	// 抜けて入り直しても増えない
+	gameRoom.sharedLives--
+	for i := 0; i < 5; i++ {
+		delete(gameRoom.Players, b.ID)
+		b = join("b", "guest:b")
+	}
+	if want := 2*startingLives - 1; gameRoom.sharedLives != want {
+		t.Fatalf("入り直しで共有の残機が %d, want %d", gameRoom.sharedLives, want)
+	}
+
+	// 上限（最大人数分）を超えない
+	for i := 0; i < 20; i++ {
+		join("x", "guest:x"+string(rune('a'+i)))
+	}
+	if gameRoom.sharedLives != livesLimit(gameRoom) {
+		t.Fatalf("共有の残機 %d が上限 %d と違う", gameRoom.sharedLives, livesLimit(gameRoom))
+	}
+
+	// リスタートで参加中のプレイヤーの分に戻る
+	resetSharedLives(gameRoom)
+	if want := len(gameRoom.Players) * startingLives; gameRoom.sharedLives != want {
+		t.Fatalf("リスタート後の共有の残機 %d, want %d", gameRoom.sharedLives, want)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	with := func(change func(r *RoomRules)) RoomRules {
+		r := defaultRules
+		change(&r)
+		return r
+	}
+	versus := with(func(r *RoomRules) { r.MaxPlayers = versusPlayers })
+	tests := []struct {
+		name  string
+		rules RoomRules
+		mode  string
+		want  error
+	}{
+		{"既定", defaultRules, modeCoop, nil},
+		{"全て変更", with(func(r *RoomRules) {
+			*r = RoomRules{MaxPlayers: 8, BossThreshold: 100, FirePower: 5, FriendlyFire: true, SharedLives: true, ItemSharing: true}
+		}), modeCoop, nil},
+		{"チーム対戦の既定", versus, modeVersus, nil},
+		{"最大人数0", with(func(r *RoomRules) { r.MaxPlayers = 0 }), modeCoop, errRulesMaxPlayers},
+		{"最大人数9", with(func(r *RoomRules) { r.MaxPlayers = maxRoomPlayers + 1 }), modeCoop, errRulesMaxPlayers},
+		{"撃破数が負", with(func(r *RoomRules) { r.BossThreshold = -1 }), modeCoop, errRulesBossThreshold},
+		{"撃破数101", with(func(r *RoomRules) { r.BossThreshold = maxBossThreshold + 1 }), modeCoop, errRulesBossThreshold},
+		{"武器レベル0", with(func(r *RoomRules) { r.FirePower = 0 }), modeCoop, errRulesFirePower},
+		{"武器レベル6", with(func(r *RoomRules) { r.FirePower = maxFirePower + 1 }), modeCoop, errRulesFirePower},
+		{"デイリーの変更", with(func(r *RoomRules) { r.ItemSharing = true }), modeDaily, errRulesDaily},
+		{"チーム対戦の人数", with(func(r *RoomRules) { r.MaxPlayers = 6 }), modeVersus, errRulesVersusPlayers},
+		{"デスマッチ1人", with(func(r *RoomRules) { r.MaxPlayers = 1 }), modeDeathmatch, errRulesPvPPlayers},
+		{"デスマッチのフレンドリーファイア", with(func(r *RoomRules) { r.FriendlyFire = true }), modeDeathmatch, errRulesFriendlyFire},
+		{"チーム対戦のフレンドリーファイア", with(func(r *RoomRules) { *r = versus; r.FriendlyFire = true }), modeVersus, errRulesFriendlyFire},
+		{"デスマッチの共有残機", with(func(r *RoomRules) { r.SharedLives = true }), modeDeathmatch, errRulesSharedLives},
+		{"ボスのいないモードの撃破数", with(func(r *RoomRules) { *r = versus; r.BossThreshold = 10 }), modeVersus, errRulesNoBoss},
+	}
+	for _, tt := range tests {
+		if err := validateRules(tt.rules, tt.mode); err != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	parse := func(query, mode string) (RoomRules, error) {
+		req := httptest.NewRequest(http.MethodGet, "/ws?"+query, nil)
+		return parseRules(echo.New().NewContext(req, httptest.NewRecorder()), mode)
+	}
+
+	rules, err := parse("maxPlayers=2&firePower=3&sharedLives=true&bossThreshold=15", modeCoop)
+	want := RoomRules{MaxPlayers: 2, FirePower: 3, SharedLives: true, BossThreshold: 15}
+	if err != nil || rules != want {
+		t.Fatalf("parseRules = %+v, %v, want %+v", rules, err, want)
+	}
+	if rules, err := parse("", modeCoop); err != nil || rules != defaultRules {
+		t.Fatalf("指定なし: %+v, %v", rules, err)
+	}
+	for _, query := range []string{"maxPlayers=two", "friendlyFire=maybe", "firePower=1.5"} {
+		if _, err := parse(query, modeCoop); err != errRulesInvalid {
+			t.Errorf("%s: %v, want %v", query, err, errRulesInvalid)
+		}
+	}
+	if _, err := parse("firePower=9", modeCoop); err != errRulesFirePower {
+		t.Errorf("範囲外の武器レベル: %v, want %v", err, errRulesFirePower)
+	}
+}
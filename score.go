@@ -0,0 +1,356 @@
+/**
+ * @file score.go
+ * @description スコアの加算と、プレイヤーへの得点の帰属
+ *
+ * 概要:
+ * - プレイヤーの弾は発射したプレイヤーのIDを持ち、撃破・ボスへのダメージをその弾の持ち主に加算する
+ * - とどめを刺していないが、ダメージを与えていたプレイヤーにはアシスト点を加算する
+ * - スコアの変化は理由付きのイベントとして記録し、次のブロードキャストで送信する
+ * - 短い間隔で撃破を続けるとコンボが増え、撃破点に倍率がかかる（間が空くとリセット）
+ * - 敵弾をすれすれで避けるとグレイズ点
+ * - レベルクリア時にノーダメージ・タイムボーナスを加算し、理由ごとの内訳を結果として送信する
+ */
+
+package main
+
+import "math"
+
+// スコアの理由
+const (
+	scoreKill       = "kill"       // 雑魚敵の撃破
+	scoreAssist     = "assist"     // 撃破のアシスト
+	scoreBossDamage = "bossDamage" // ボスへのダメージ
+	scoreBossKill   = "bossKill"   // ボスの撃破
+	scoreBomb       = "bomb"       // ボムによる撃破
+	scoreGraze      = "graze"      // 敵弾のグレイズ
+	scoreLevelClear = "levelClear" // レベルクリアのボーナス
+	scoreNoDamage   = "noDamage"   // ノーダメージでのレベルクリア
+	scoreTimeBonus  = "timeBonus"  // 早期クリアのボーナス
+	scoreFrag       = "frag"       // 他のプレイヤーの撃墜（デスマッチ）
+	scoreRoundWin   = "roundWin"   // ラウンドの勝利（チーム対戦）
+)
+
+// 理由ごとの基本得点（ボスへのダメージはダメージ1あたり）
+var scorePoints = map[string]int{
+	scoreKill:       10,
+	scoreAssist:     5,
+	scoreBossDamage: 1,
+	scoreBossKill:   100,
+	scoreBomb:       10,
+	scoreGraze:      2,
+	scoreLevelClear: 500,
+	scoreNoDamage:   1000,
+	scoreTimeBonus:  20, // 基準時間より早かった1秒あたり
+	scoreFrag:       100,
+	scoreRoundWin:   300,
+}
+
+// コンボ倍率がかかる理由
+var comboReasons = map[string]bool{
+	scoreKill:     true,
+	scoreBossKill: true,
+}
+
+const (
+	// 撃破後、コンボが途切れるまでのティック数
+	comboWindowTicks = 120
+	// 倍率が上がるコンボ数の刻み
+	comboStep = 5
+	// 刻みごとに増える倍率
+	comboStepBonus = 0.5
+	// コンボ倍率の上限
+	maxComboMultiplier = 3.0
+	// グレイズ判定の距離（喰らい判定の中心から敵弾の中心まで）
+	grazeRadius = 24.0
+	// タイムボーナスの基準時間（秒）
+	timeBonusParSeconds = 180
+)
+
+/**
+ * レベル内のプレイヤー成績構造体
+ * @property {int} Kills - 撃破数
+ * @property {int} Grazes - グレイズ数
+ * @property {int} MaxCombo - 最大コンボ数
+ * @property {bool} Damaged - ダメージを受けたか
+ * @property {map[string]int} Points - 理由ごとの得点
+ */
+type StageStats struct {
+	Kills    int
+	Grazes   int
+	MaxCombo int
+	Damaged  bool
+	Points   map[string]int
+}
+
+/**
+ * プレイヤーごとの結果構造体
+ * @property {string} PlayerID - プレイヤーID
+ * @property {string} Name - プレイヤー名
+ * @property {int} Kills - 撃破数
+ * @property {int} Grazes - グレイズ数
+ * @property {int} MaxCombo - 最大コンボ数
+ * @property {bool} NoDamage - ノーダメージだったか
+ * @property {map[string]int} Points - 理由ごとの得点内訳（ボーナスを含む）
+ * @property {int} Total - レベル内の合計得点
+ * @property {int} Score - 現在の総スコア
+ */
+type PlayerResult struct {
+	PlayerID string         `json:"playerId"`
+	Name     string         `json:"name"`
+	Kills    int            `json:"kills"`
+	Grazes   int            `json:"grazes"`
+	MaxCombo int            `json:"maxCombo"`
+	NoDamage bool           `json:"noDamage"`
+	Points   map[string]int `json:"points"`
+	Total    int            `json:"total"`
+	Score    int            `json:"score"`
+}
+
+/**
+ * レベルの結果構造体
+ * @property {int} Level - レベル番号
+ * @property {bool} Cleared - クリアしたか（falseならゲームオーバー）
+ * @property {int} Seconds - レベル開始からの経過秒数
+ * @property {[]PlayerResult} Players - プレイヤーごとの結果
+ */
+type StageResult struct {
+	Level   int            `json:"level"`
+	Cleared bool           `json:"cleared"`
+	Seconds int            `json:"seconds"`
+	Players []PlayerResult `json:"players"`
+}
+
+/**
+ * スコア変化イベント構造体
+ * @property {string} PlayerID - 得点したプレイヤーのID
+ * @property {int} Points - 加算された得点（倍率適用後）
+ * @property {string} Reason - 理由
+ */
+type ScoreEvent struct {
+	PlayerID string `json:"playerId"`
+	Points   int    `json:"points"`
+	Reason   string `json:"reason"`
+}
+
+/**
+ * コンボ数に応じた倍率
+ * @param {int} combo - コンボ数
+ * @returns {float64} - 倍率
+ */
+func comboMultiplier(combo int) float64 {
+	return math.Min(1+float64(combo/comboStep)*comboStepBonus, maxComboMultiplier)
+}
+
+/**
+ * スコアを加算する（コンボ倍率とスコア倍率効果を反映）
+ * 同じティックの同じプレイヤー・理由のイベントは1つにまとめる。チーム対戦ではチームのスコアにも加算する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー
+ * @param {int} points - 基本得点
+ * @param {string} reason - 理由
+ */
+func awardScore(gameRoom *GameRoom, p *Player, points int, reason string) {
+	if comboReasons[reason] {
+		points = int(float64(points) * comboMultiplier(p.Combo))
+	}
+	if hasEffect(p, itemMultiplier) {
+		points *= scoreMultiplierBonus
+	}
+	p.Score += points
+	if p.Stage.Points == nil {
+		p.Stage.Points = make(map[string]int)
+	}
+	p.Stage.Points[reason] += points
+	if t := playerTeam(gameRoom, p); t != nil {
+		t.Score += points
+	}
+
+	for i := range gameRoom.scoreEvents {
+		ev := &gameRoom.scoreEvents[i]
+		if ev.PlayerID == p.ID && ev.Reason == reason {
+			ev.Points += points
+			return
+		}
+	}
+	gameRoom.scoreEvents = append(gameRoom.scoreEvents, ScoreEvent{PlayerID: p.ID, Points: points, Reason: reason})
+}
+
+/**
+ * 弾の持ち主にスコアを加算する（持ち主が退出済みなら何もしない）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {string} owner - 弾の持ち主のプレイヤーID
+ * @param {int} points - 基本得点
+ * @param {string} reason - 理由
+ */
+func awardOwner(gameRoom *GameRoom, owner string, points int, reason string) {
+	if p, ok := gameRoom.Players[owner]; ok {
+		awardScore(gameRoom, p, points, reason)
+	}
+}
+
+/**
+ * 標的にダメージを与えたプレイヤーを記録する（アシスト判定用）
+ * @param {*Entity} target - 標的
+ * @param {string} owner - 弾の持ち主のプレイヤーID
+ */
+func recordAttacker(target *Entity, owner string) {
+	if owner == "" {
+		return
+	}
+	for _, id := range target.attackers {
+		if id == owner {
+			return
+		}
+	}
+	target.attackers = append(target.attackers, owner)
+}
+
+/**
+ * 撃破の得点を加算する
+ * とどめを刺したプレイヤーに撃破点、それ以外にダメージを与えたプレイヤーにアシスト点
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} target - 倒された標的
+ * @param {string} killer - とどめを刺したプレイヤーのID
+ * @param {string} reason - 撃破点の理由
+ */
+func awardKill(gameRoom *GameRoom, target *Entity, killer string, reason string) {
+	if p, ok := gameRoom.Players[killer]; ok {
+		addCombo(p)
+		p.Stage.Kills++
+	}
+	awardOwner(gameRoom, killer, scorePoints[reason], reason)
+	for _, id := range target.attackers {
+		if id != killer {
+			awardOwner(gameRoom, id, scorePoints[scoreAssist], scoreAssist)
+		}
+	}
+}
+
+/**
+ * 記録されたスコア変化イベントを取り出す（ブロードキャスト時に呼ぶ）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @returns {[]ScoreEvent} - 前回の取り出し以降のイベント
+ */
+func takeScoreEvents(gameRoom *GameRoom) []ScoreEvent {
+	events := gameRoom.scoreEvents
+	gameRoom.scoreEvents = nil
+	return events
+}
+
+/**
+ * コンボを1つ増やし、途切れるまでの時間をリセットする
+ * @param {*Player} p - プレイヤー
+ */
+func addCombo(p *Player) {
+	p.Combo++
+	p.comboTimer = comboWindowTicks
+	if p.Combo > p.Stage.MaxCombo {
+		p.Stage.MaxCombo = p.Combo
+	}
+}
+
+/**
+ * スコアシステム: レベル・ゲーム全体の経過時間とコンボの残り時間を進める
+ */
+func scoreSystem(gameRoom *GameRoom) {
+	gameRoom.levelTicks++
+	gameRoom.runTicks++
+	for _, p := range gameRoom.Players {
+		if p.comboTimer > 0 {
+			p.comboTimer--
+			if p.comboTimer == 0 {
+				p.Combo = 0
+			}
+		}
+	}
+}
+
+/**
+ * グレイズシステム: 生存中のプレイヤーの近くを通過した敵弾にグレイズ点を与える
+ * 命中した弾は衝突システムで取り除かれているため、ここでは対象にならない
+ */
+func grazeSystem(gameRoom *GameRoom) {
+	for _, b := range gameRoom.Bullets {
+		if b.Layer != layerEnemyShot || b.removed || b.grazed {
+			continue
+		}
+		bx := b.X + float64(b.Width)/2
+		by := b.Y + float64(b.Height)/2
+		for _, p := range gameRoom.Players {
+			if p.Health.Current <= 0 {
+				continue
+			}
+			cx, cy := p.X+float64(p.Width)/2, p.Y+float64(p.Height)/2
+			if p.Shape != nil && p.Shape.Kind == shapeCircle {
+				cx, cy = p.X+p.Shape.A.X, p.Y+p.Shape.A.Y
+			}
+			if math.Hypot(bx-cx, by-cy) <= grazeRadius {
+				b.grazed = true
+				p.Stage.Grazes++
+				awardScore(gameRoom, p, scorePoints[scoreGraze], scoreGraze)
+				break
+			}
+		}
+	}
+}
+
+/**
+ * レベルクリアのボーナスを加算する
+ * クリアボーナスに加え、ノーダメージと基準時間より早いクリアにボーナス
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func awardStageBonuses(gameRoom *GameRoom) {
+	seconds := gameRoom.levelTicks / 60
+	for _, p := range gameRoom.Players {
+		awardScore(gameRoom, p, scorePoints[scoreLevelClear], scoreLevelClear)
+		if !p.Stage.Damaged {
+			awardScore(gameRoom, p, scorePoints[scoreNoDamage], scoreNoDamage)
+		}
+		if seconds < timeBonusParSeconds {
+			awardScore(gameRoom, p, (timeBonusParSeconds-seconds)*scorePoints[scoreTimeBonus], scoreTimeBonus)
+		}
+	}
+}
+
+/**
+ * レベルの結果を記録し、レベル内の成績とコンボをリセットする
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {bool} cleared - クリアしたか（falseならゲームオーバー）
+ */
+func finishStage(gameRoom *GameRoom, cleared bool) {
+	result := &StageResult{
+		Level:   gameRoom.Level,
+		Cleared: cleared,
+		Seconds: gameRoom.levelTicks / 60,
+	}
+	for _, p := range gameRoom.Players {
+		total := 0
+		for _, points := range p.Stage.Points {
+			total += points
+		}
+		result.Players = append(result.Players, PlayerResult{
+			PlayerID: p.ID,
+			Name:     p.Name,
+			Kills:    p.Stage.Kills,
+			Grazes:   p.Stage.Grazes,
+			MaxCombo: p.Stage.MaxCombo,
+			NoDamage: !p.Stage.Damaged,
+			Points:   p.Stage.Points,
+			Total:    total,
+			Score:    p.Score,
+		})
+		resetStage(p)
+	}
+	gameRoom.Results = result
+	gameRoom.levelTicks = 0
+}
+
+/**
+ * プレイヤーのレベル内の成績とコンボをリセットする
+ * @param {*Player} p - プレイヤー
+ */
+func resetStage(p *Player) {
+	p.Stage = StageStats{}
+	p.Combo = 0
+	p.comboTimer = 0
+}
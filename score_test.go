@@ -0,0 +1,214 @@
+/**
+ * @file score_test.go
+ * @description スコアの加算と得点の帰属のテスト
+ *
+ * 概要:
+ * - 撃破点がとどめを刺した弾の持ち主に、アシスト点がそれ以外の攻撃者に入ることを確認する
+ * - ボスへのダメージ点が弾の持ち主に入り、退出済みの持ち主には何も起きないことを確認する
+ * - 同じティックの同じ理由のスコアイベントが1つにまとまることを確認する
+ * - コンボ倍率が5コンボごとに上がり、撃破が途切れるとコンボがリセットされることを確認する
+ * - 敵弾のグレイズが1発につき一度だけ加算されることを確認する
+ * - レベルクリア時のボーナスと結果の内訳を確認する
+ */
+
+package main
+
+import "testing"
+
+/**
+ * 弾を標的に直接命中させる
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {*Player} p - 弾の持ち主
+ * @param {*Entity} target - 標的
+ * @param {int} damage - ダメージ
+ */
+func hitWith(gameRoom *GameRoom, p *Player, target *Entity, damage int) {
+	b, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, -6, 5, 10, damage, 0)
+	shotHitsTarget(gameRoom, b, target)
+}
+
+func TestKillAndAssistCredit(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	helper := testPlayer(gameRoom, "helper", 100, 500)
+	killer := testPlayer(gameRoom, "killer", 200, 500)
+	bystander := testPlayer(gameRoom, "bystander", 300, 500)
+	tank := stillEnemy(gameRoom, "tank", 100, 100)
+
+	hitWith(gameRoom, helper, tank, 3)
+	hitWith(gameRoom, helper, tank, 3) // 同じ攻撃者は一度だけ記録される
+	if helper.Score != 0 {
+		t.Fatalf("撃破前に得点: %d", helper.Score)
+	}
+	hitWith(gameRoom, killer, tank, 5)
+
+	if killer.Score != scorePoints[scoreKill] || killer.Stage.Kills != 1 {
+		t.Errorf("とどめ: %d 点・撃破数 %d, want %d 点・1", killer.Score, killer.Stage.Kills, scorePoints[scoreKill])
+	}
+	if helper.Score != scorePoints[scoreAssist] || helper.Stage.Kills != 0 {
+		t.Errorf("アシスト: %d 点・撃破数 %d, want %d 点・0", helper.Score, helper.Stage.Kills, scorePoints[scoreAssist])
+	}
+	if bystander.Score != 0 {
+		t.Errorf("攻撃していないプレイヤーに %d 点", bystander.Score)
+	}
+}
+
+func TestBossDamageCredit(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+	gone := testPlayer(gameRoom, "gone", 200, 500)
+	spawnBosses(gameRoom)
+	var boss *Entity
+	for _, b := range gameRoom.Bosses {
+		boss = b
+	}
+
+	hitWith(gameRoom, p, boss, 4)
+	hitWith(gameRoom, p, boss, 3)
+	if want := 7 * scorePoints[scoreBossDamage]; p.Score != want {
+		t.Errorf("ボスへのダメージ点 %d, want %d", p.Score, want)
+	}
+
+	// 発射後に退出したプレイヤーの弾もダメージは与えるが、得点は誰にも入らない
+	b, _ := spawnPlayerBullet(gameRoom, gone, defaultWeapon, 0, 0, -6, 5, 10, 5, 0)
+	delete(gameRoom.Players, gone.ID)
+	before := boss.Health.Current
+	shotHitsTarget(gameRoom, b, boss)
+	if boss.Health.Current != before-5 {
+		t.Errorf("退出したプレイヤーの弾のダメージ %d, want 5", before-boss.Health.Current)
+	}
+	if p.Score != 7*scorePoints[scoreBossDamage] {
+		t.Errorf("他のプレイヤーの弾で得点が増えた: %d", p.Score)
+	}
+}
+
+func TestScoreEventsMerge(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+	awardScore(gameRoom, p, 3, scoreBossDamage)
+	awardScore(gameRoom, p, 4, scoreBossDamage)
+	awardScore(gameRoom, p, 10, scoreKill)
+
+	events := takeScoreEvents(gameRoom)
+	if len(events) != 2 || events[0] != (ScoreEvent{PlayerID: "p1", Points: 7, Reason: scoreBossDamage}) {
+		t.Fatalf("スコアイベント %+v", events)
+	}
+	if events := takeScoreEvents(gameRoom); len(events) != 0 {
+		t.Fatalf("取り出し後もイベントが残っている: %+v", events)
+	}
+}
+
+func TestComboMultiplier(t *testing.T) {
+	tests := []struct {
+		combo int
+		want  float64
+	}{
+		{0, 1}, {4, 1}, {5, 1.5}, {9, 1.5}, {10, 2}, {20, 3}, {100, maxComboMultiplier},
+	}
+	for _, tt := range tests {
+		if got := comboMultiplier(tt.combo); got != tt.want {
+			t.Errorf("comboMultiplier(%d) = %v, want %v", tt.combo, got, tt.want)
+		}
+	}
+}
+
+func TestComboDecay(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 100, 500)
+	target := stillEnemy(gameRoom, "grunt", 100, 100)
+
+	for i := 0; i < 9; i++ {
+		addCombo(p)
+		for tick := 0; tick < comboWindowTicks-1; tick++ {
+			scoreSystem(gameRoom)
+		}
+	}
+	if p.Combo != 9 {
+		t.Fatalf("間隔内の撃破でコンボ %d, want 9", p.Combo)
+	}
+
+	// 10コンボ目の撃破点には2倍の倍率がかかる
+	awardKill(gameRoom, target, p.ID, scoreKill)
+	if want := 2 * scorePoints[scoreKill]; p.Score != want {
+		t.Errorf("10コンボ目の撃破点 %d, want %d", p.Score, want)
+	}
+
+	for tick := 0; tick < comboWindowTicks-1; tick++ {
+		scoreSystem(gameRoom)
+	}
+	if p.Combo != 10 {
+		t.Fatalf("途切れる前にコンボが %d になった", p.Combo)
+	}
+	scoreSystem(gameRoom)
+	if p.Combo != 0 || p.Stage.MaxCombo != 10 {
+		t.Errorf("途切れた後: コンボ %d・最大 %d, want 0・10", p.Combo, p.Stage.MaxCombo)
+	}
+}
+
+func TestGrazeAwardsOnce(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 400)
+	cx, cy := p.X+p.Shape.A.X, p.Y+p.Shape.A.Y
+	source := &Entity{Transform: transformAt(0, 0, 0, 0)}
+
+	// 1発は喰らい判定の中心から14ピクセル、もう1発はグレイズ判定の外（34ピクセル）を通る
+	for _, dx := range []float64{grazeRadius - 10, grazeRadius + 10} {
+		spawnBossBullet(gameRoom, source, 0, 0)
+		for _, b := range gameRoom.Bullets {
+			if b.X == 0 {
+				b.X, b.Y = cx+dx-float64(b.Width)/2, cy-float64(b.Height)/2
+			}
+		}
+	}
+
+	grazeSystem(gameRoom)
+	grazeSystem(gameRoom)
+	if p.Stage.Grazes != 1 || p.Score != scorePoints[scoreGraze] {
+		t.Errorf("グレイズ %d 回・%d 点, want 1 回・%d 点", p.Stage.Grazes, p.Score, scorePoints[scoreGraze])
+	}
+
+	// 倒れているプレイヤーはグレイズしない
+	p.Health.Current = 0
+	for _, b := range gameRoom.Bullets {
+		b.grazed = false
+	}
+	grazeSystem(gameRoom)
+	if p.Stage.Grazes != 1 {
+		t.Errorf("倒れたプレイヤーがグレイズした: %d", p.Stage.Grazes)
+	}
+}
+
+func TestStageBonuses(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	clean := testPlayer(gameRoom, "clean", 100, 500)
+	hurt := testPlayer(gameRoom, "hurt", 200, 500)
+	hurt.Stage.Damaged = true
+	gameRoom.levelTicks = 100 * 60
+
+	awardStageBonuses(gameRoom)
+	timeBonus := (timeBonusParSeconds - 100) * scorePoints[scoreTimeBonus]
+	if want := scorePoints[scoreLevelClear] + scorePoints[scoreNoDamage] + timeBonus; clean.Score != want {
+		t.Errorf("ノーダメージ: %d 点, want %d", clean.Score, want)
+	}
+	if want := scorePoints[scoreLevelClear] + timeBonus; hurt.Score != want {
+		t.Errorf("被弾あり: %d 点, want %d", hurt.Score, want)
+	}
+
+	finishStage(gameRoom, true)
+	for _, r := range gameRoom.Results.Players {
+		p := gameRoom.Players[r.PlayerID]
+		if r.Total != p.Score || r.NoDamage != (p == clean) || r.Points[scoreTimeBonus] != timeBonus {
+			t.Errorf("%s の結果 %+v", r.PlayerID, r)
+		}
+	}
+	if gameRoom.Results.Seconds != 100 || gameRoom.levelTicks != 0 || hurt.Stage.Damaged {
+		t.Error("レベル終了後に経過時間と成績がリセットされない")
+	}
+
+	// 基準時間を過ぎたクリアにタイムボーナスはない
+	gameRoom.levelTicks = (timeBonusParSeconds + 10) * 60
+	before := clean.Score
+	awardStageBonuses(gameRoom)
+	if got := clean.Score - before; got != scorePoints[scoreLevelClear]+scorePoints[scoreNoDamage] {
+		t.Errorf("基準時間後のボーナス %d", got)
+	}
+}
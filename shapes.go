@@ -0,0 +1,354 @@
+/**
+ * @file shapes.go
+ * @description エンティティごとの衝突形状とナローフェーズ判定
+ *
+ * 概要:
+ * - 形状: 矩形（既定）・円・カプセル・凸多角形・複合形状
+ * - 円とカプセルは線分からの距離、多角形同士は分離軸定理（SAT）で判定
+ * - プレイヤーは見た目より小さい「喰らい判定（ハートボックス）」を持つ
+ *
+ * 形状の座標はエンティティ左上を原点とするローカル座標で定義する
+ */
+
+package main
+
+import "math"
+
+// 衝突形状の種類
+const (
+	shapeCircle   = "circle"
+	shapeCapsule  = "capsule"
+	shapePolygon  = "polygon"
+	shapeCompound = "compound"
+)
+
+// プレイヤーの喰らい判定（30x30の機体中央の小さな円）
+var playerHurtbox = circleShape(15, 15, 6)
+
+// ボス弾の衝突形状（10x10の円）
+var bossBulletShape = circleShape(5, 5, 5)
+
+/**
+ * 2次元ベクトル構造体
+ * @property {float64} X - X成分
+ * @property {float64} Y - Y成分
+ */
+type Vec2 struct {
+	X float64
+	Y float64
+}
+
+/**
+ * 衝突形状構造体
+ * @property {string} Kind - 形状の種類
+ * @property {Vec2} A - 円の中心、またはカプセルの線分の始点（ローカル座標）
+ * @property {Vec2} B - カプセルの線分の終点（ローカル座標）
+ * @property {float64} Radius - 円・カプセルの半径
+ * @property {[]Vec2} Points - 凸多角形の頂点（ローカル座標、時計回りまたは反時計回り）
+ * @property {[]*Shape} Children - 複合形状を構成する形状
+ */
+type Shape struct {
+	Kind     string
+	A        Vec2
+	B        Vec2
+	Radius   float64
+	Points   []Vec2
+	Children []*Shape
+}
+
+/**
+ * ワールド座標に配置した判定用の基本形状
+ * 円は始点と終点が同じカプセルとして扱う
+ * @property {bool} polygon - 多角形ならtrue、カプセルならfalse
+ * @property {Vec2} a - カプセルの始点
+ * @property {Vec2} b - カプセルの終点
+ * @property {float64} radius - カプセルの半径
+ * @property {[]Vec2} points - 多角形の頂点
+ */
+type primitive struct {
+	polygon bool
+	a       Vec2
+	b       Vec2
+	radius  float64
+	points  []Vec2
+}
+
+/**
+ * 円形状を作成する
+ * @param {float64} x - 中心のX座標（ローカル）
+ * @param {float64} y - 中心のY座標（ローカル）
+ * @param {float64} r - 半径
+ * @returns {*Shape} - 円形状
+ */
+func circleShape(x, y, r float64) *Shape {
+	return &Shape{Kind: shapeCircle, A: Vec2{x, y}, Radius: r}
+}
+
+/**
+ * カプセル形状を作成する
+ * @param {Vec2} a - 線分の始点（ローカル）
+ * @param {Vec2} b - 線分の終点（ローカル）
+ * @param {float64} r - 半径
+ * @returns {*Shape} - カプセル形状
+ */
+func capsuleShape(a, b Vec2, r float64) *Shape {
+	return &Shape{Kind: shapeCapsule, A: a, B: b, Radius: r}
+}
+
+/**
+ * 凸多角形形状を作成する
+ * @param {...Vec2} points - 頂点（ローカル）
+ * @returns {*Shape} - 多角形形状
+ */
+func polygonShape(points ...Vec2) *Shape {
+	return &Shape{Kind: shapePolygon, Points: points}
+}
+
+/**
+ * 複合形状を作成する
+ * @param {...*Shape} children - 構成する形状
+ * @returns {*Shape} - 複合形状
+ */
+func compoundShape(children ...*Shape) *Shape {
+	return &Shape{Kind: shapeCompound, Children: children}
+}
+
+/**
+ * エンティティの衝突形状をワールド座標の基本形状に変換する
+ * 形状が未設定の場合は Width/Height の矩形を使用する
+ * @param {*Entity} e - エンティティ
+ * @param {[]primitive} out - 追加先
+ * @returns {[]primitive} - 基本形状のリスト
+ */
+func worldPrimitives(e *Entity, out []primitive) []primitive {
+	if e.Shape == nil {
+		w, h := float64(e.Width), float64(e.Height)
+		return append(out, primitive{
+			polygon: true,
+			points:  []Vec2{{e.X, e.Y}, {e.X + w, e.Y}, {e.X + w, e.Y + h}, {e.X, e.Y + h}},
+		})
+	}
+	return appendShapePrimitives(e.Shape, e.X, e.Y, out)
+}
+
+/**
+ * 形状をワールド座標に平行移動して基本形状リストに追加する
+ * @param {*Shape} s - 形状
+ * @param {float64} ox - エンティティのX座標
+ * @param {float64} oy - エンティティのY座標
+ * @param {[]primitive} out - 追加先
+ * @returns {[]primitive} - 基本形状のリスト
+ */
+func appendShapePrimitives(s *Shape, ox, oy float64, out []primitive) []primitive {
+	switch s.Kind {
+	case shapeCircle:
+		c := Vec2{s.A.X + ox, s.A.Y + oy}
+		out = append(out, primitive{a: c, b: c, radius: s.Radius})
+	case shapeCapsule:
+		out = append(out, primitive{
+			a:      Vec2{s.A.X + ox, s.A.Y + oy},
+			b:      Vec2{s.B.X + ox, s.B.Y + oy},
+			radius: s.Radius,
+		})
+	case shapePolygon:
+		points := make([]Vec2, len(s.Points))
+		for i, p := range s.Points {
+			points[i] = Vec2{p.X + ox, p.Y + oy}
+		}
+		out = append(out, primitive{polygon: true, points: points})
+	case shapeCompound:
+		for _, child := range s.Children {
+			out = appendShapePrimitives(child, ox, oy, out)
+		}
+	}
+	return out
+}
+
+/**
+ * 2つのエンティティの衝突形状が重なっているか判定する（ナローフェーズ）
+ * @param {*Entity} a - エンティティA
+ * @param {*Entity} b - エンティティB
+ * @returns {bool} - 重なっている場合true
+ */
+func shapesOverlap(a, b *Entity) bool {
+	var bufA, bufB [4]primitive
+	pa := worldPrimitives(a, bufA[:0])
+	pb := worldPrimitives(b, bufB[:0])
+	for i := range pa {
+		for j := range pb {
+			if primitivesOverlap(&pa[i], &pb[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/**
+ * 基本形状同士の重なり判定
+ * @param {*primitive} a - 基本形状A
+ * @param {*primitive} b - 基本形状B
+ * @returns {bool} - 重なっている場合true
+ */
+func primitivesOverlap(a, b *primitive) bool {
+	switch {
+	case a.polygon && b.polygon:
+		return polygonsOverlap(a.points, b.points)
+	case a.polygon:
+		return capsulePolygonOverlap(b, a.points)
+	case b.polygon:
+		return capsulePolygonOverlap(a, b.points)
+	default:
+		r := a.radius + b.radius
+		return segmentSegmentDistSq(a.a, a.b, b.a, b.b) <= r*r
+	}
+}
+
+/**
+ * 分離軸定理による凸多角形同士の重なり判定
+ * @param {[]Vec2} a - 多角形Aの頂点
+ * @param {[]Vec2} b - 多角形Bの頂点
+ * @returns {bool} - 重なっている場合true
+ */
+func polygonsOverlap(a, b []Vec2) bool {
+	return !hasSeparatingAxis(a, b) && !hasSeparatingAxis(b, a)
+}
+
+/**
+ * 多角形 poly の辺の法線のうち、2つの多角形を分離する軸があるか調べる
+ * @param {[]Vec2} poly - 軸を取る多角形
+ * @param {[]Vec2} other - もう一方の多角形
+ * @returns {bool} - 分離軸が見つかった場合true
+ */
+func hasSeparatingAxis(poly, other []Vec2) bool {
+	for i := range poly {
+		p1 := poly[i]
+		p2 := poly[(i+1)%len(poly)]
+		axis := Vec2{-(p2.Y - p1.Y), p2.X - p1.X}
+		minA, maxA := projectPolygon(poly, axis)
+		minB, maxB := projectPolygon(other, axis)
+		if maxA < minB || maxB < minA {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 多角形を軸に射影した範囲を求める
+ * @param {[]Vec2} poly - 多角形の頂点
+ * @param {Vec2} axis - 射影軸
+ * @returns {float64, float64} - 射影の最小値と最大値
+ */
+func projectPolygon(poly []Vec2, axis Vec2) (float64, float64) {
+	lo := math.Inf(1)
+	hi := math.Inf(-1)
+	for _, p := range poly {
+		d := p.X*axis.X + p.Y*axis.Y
+		lo = math.Min(lo, d)
+		hi = math.Max(hi, d)
+	}
+	return lo, hi
+}
+
+/**
+ * カプセル（円を含む）と凸多角形の重なり判定
+ * 線分が多角形の内部にあるか、多角形の辺との距離が半径以下なら重なり
+ * @param {*primitive} c - カプセル
+ * @param {[]Vec2} poly - 多角形の頂点
+ * @returns {bool} - 重なっている場合true
+ */
+func capsulePolygonOverlap(c *primitive, poly []Vec2) bool {
+	if pointInPolygon(c.a, poly) || pointInPolygon(c.b, poly) {
+		return true
+	}
+	r2 := c.radius * c.radius
+	for i := range poly {
+		if segmentSegmentDistSq(c.a, c.b, poly[i], poly[(i+1)%len(poly)]) <= r2 {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 点が凸多角形の内部にあるか判定する（頂点の並び順は問わない）
+ * @param {Vec2} p - 点
+ * @param {[]Vec2} poly - 多角形の頂点
+ * @returns {bool} - 内部または辺上ならtrue
+ */
+func pointInPolygon(p Vec2, poly []Vec2) bool {
+	sign := 0.0
+	for i := range poly {
+		a := poly[i]
+		b := poly[(i+1)%len(poly)]
+		cross := (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+		if cross == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (sign > 0) != (cross > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * 点と線分の距離の2乗
+ * @param {Vec2} p - 点
+ * @param {Vec2} a - 線分の始点
+ * @param {Vec2} b - 線分の終点
+ * @returns {float64} - 距離の2乗
+ */
+func pointSegmentDistSq(p, a, b Vec2) float64 {
+	abx, aby := b.X-a.X, b.Y-a.Y
+	lenSq := abx*abx + aby*aby
+	t := 0.0
+	if lenSq > 0 {
+		t = ((p.X-a.X)*abx + (p.Y-a.Y)*aby) / lenSq
+		t = math.Max(0, math.Min(1, t))
+	}
+	dx := a.X + abx*t - p.X
+	dy := a.Y + aby*t - p.Y
+	return dx*dx + dy*dy
+}
+
+/**
+ * 線分同士の距離の2乗（交差している場合は0）
+ * @param {Vec2} a1 - 線分1の始点
+ * @param {Vec2} a2 - 線分1の終点
+ * @param {Vec2} b1 - 線分2の始点
+ * @param {Vec2} b2 - 線分2の終点
+ * @returns {float64} - 距離の2乗
+ */
+func segmentSegmentDistSq(a1, a2, b1, b2 Vec2) float64 {
+	if segmentsIntersect(a1, a2, b1, b2) {
+		return 0
+	}
+	return math.Min(
+		math.Min(pointSegmentDistSq(a1, b1, b2), pointSegmentDistSq(a2, b1, b2)),
+		math.Min(pointSegmentDistSq(b1, a1, a2), pointSegmentDistSq(b2, a1, a2)),
+	)
+}
+
+/**
+ * 線分同士が交差しているか判定する
+ * @param {Vec2} a1 - 線分1の始点
+ * @param {Vec2} a2 - 線分1の終点
+ * @param {Vec2} b1 - 線分2の始点
+ * @param {Vec2} b2 - 線分2の終点
+ * @returns {bool} - 交差している場合true
+ */
+func segmentsIntersect(a1, a2, b1, b2 Vec2) bool {
+	cross := func(o, p, q Vec2) float64 {
+		return (p.X-o.X)*(q.Y-o.Y) - (p.Y-o.Y)*(q.X-o.X)
+	}
+	d1 := cross(b1, b2, a1)
+	d2 := cross(b1, b2, a2)
+	d3 := cross(a1, a2, b1)
+	d4 := cross(a1, a2, b2)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
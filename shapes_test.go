@@ -0,0 +1,147 @@
+/**
+ * @file shapes_test.go
+ * @description 衝突形状のナローフェーズ判定のテスト
+ *
+ * 概要:
+ * - 多角形同士（分離軸定理）・円・カプセル・複合形状の重なり判定を表で確認する
+ * - 点と多角形、線分同士の距離などの幾何関数を直接確認する
+ */
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+/**
+ * 衝突形状を持つテスト用のエンティティを作成する
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @param {*Shape} shape - 衝突形状（nilなら10x10の矩形）
+ * @returns {*Entity} - エンティティ
+ */
+func shapeEntity(x, y float64, shape *Shape) *Entity {
+	return &Entity{Transform: transformAt(x, y, 10, 10), Collider: &Collider{Shape: shape}}
+}
+
+// 一辺 size の正方形
+func square(size float64) *Shape {
+	return polygonShape(Vec2{0, 0}, Vec2{size, 0}, Vec2{size, size}, Vec2{0, size})
+}
+
+// 中心 (cx, cy)・中心から頂点までが r のひし形
+func diamond(cx, cy, r float64) *Shape {
+	return polygonShape(Vec2{cx, cy - r}, Vec2{cx + r, cy}, Vec2{cx, cy + r}, Vec2{cx - r, cy})
+}
+
+// 横に並んだ n 個の円（半径2、間隔10）の複合形状
+func circleRow(n int) *Shape {
+	children := make([]*Shape, n)
+	for i := range children {
+		children[i] = circleShape(float64(i)*10, 0, 2)
+	}
+	return compoundShape(children...)
+}
+
+func TestShapesOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *Entity
+		want bool
+	}{
+		// 多角形同士
+		{"辺が接する正方形", shapeEntity(0, 0, square(10)), shapeEntity(10, 0, square(10)), true},
+		{"離れた正方形", shapeEntity(0, 0, square(10)), shapeEntity(11, 0, square(10)), false},
+		{"内側にある正方形", shapeEntity(0, 0, square(100)), shapeEntity(40, 40, square(10)), true},
+		{"外側から包む正方形", shapeEntity(40, 40, square(10)), shapeEntity(0, 0, square(100)), true},
+		{"ひし形の軸でのみ分離", shapeEntity(0, 0, square(10)), shapeEntity(0, 0, diamond(13, 13, 5)), false},
+		{"ひし形が角に重なる", shapeEntity(0, 0, square(10)), shapeEntity(0, 0, diamond(13, 13, 7)), true},
+		{"形状なしは矩形", shapeEntity(0, 0, nil), shapeEntity(5, 5, square(10)), true},
+
+		// 円・カプセルと多角形
+		{"多角形の内側の円", shapeEntity(0, 0, square(100)), shapeEntity(50, 50, circleShape(0, 0, 5)), true},
+		{"角の近くで外れる円", shapeEntity(0, 0, square(100)), shapeEntity(105, 105, circleShape(0, 0, 5)), false},
+		{"辺に届く円", shapeEntity(0, 0, square(100)), shapeEntity(104, 50, circleShape(0, 0, 5)), true},
+		{"多角形を貫くカプセル", shapeEntity(0, 0, square(10)), shapeEntity(0, 0, capsuleShape(Vec2{-20, 5}, Vec2{30, 5}, 1)), true},
+		{"多角形の横を通るカプセル", shapeEntity(0, 0, square(10)), shapeEntity(0, 0, capsuleShape(Vec2{-20, 15}, Vec2{30, 15}, 4)), false},
+
+		// カプセル同士
+		{"平行で届かないカプセル", shapeEntity(0, 0, capsuleShape(Vec2{0, 0}, Vec2{50, 0}, 4)), shapeEntity(0, 10, capsuleShape(Vec2{0, 0}, Vec2{50, 0}, 4)), false},
+		{"平行で届くカプセル", shapeEntity(0, 0, capsuleShape(Vec2{0, 0}, Vec2{50, 0}, 6)), shapeEntity(0, 10, capsuleShape(Vec2{0, 0}, Vec2{50, 0}, 5)), true},
+		{"交差するカプセル", shapeEntity(0, 0, capsuleShape(Vec2{0, 0}, Vec2{50, 50}, 1)), shapeEntity(0, 0, capsuleShape(Vec2{0, 50}, Vec2{50, 0}, 1)), true},
+		{"端点同士が近いカプセル", shapeEntity(0, 0, capsuleShape(Vec2{0, 0}, Vec2{10, 0}, 2)), shapeEntity(0, 0, capsuleShape(Vec2{13, 0}, Vec2{30, 0}, 2)), true},
+		{"円同士", shapeEntity(0, 0, circleShape(0, 0, 3)), shapeEntity(7, 0, circleShape(0, 0, 3)), false},
+
+		// 複合形状（基本形状が4つを超えるとスタック上のバッファからあふれる）
+		{"6番目の子だけが重なる", shapeEntity(0, 0, circleRow(6)), shapeEntity(50, 0, circleShape(0, 0, 1)), true},
+		{"どの子とも重ならない", shapeEntity(0, 0, circleRow(6)), shapeEntity(65, 0, circleShape(0, 0, 1)), false},
+		{"複合形状同士の最後の子", shapeEntity(0, 0, circleRow(6)), shapeEntity(50, 3, circleRow(6)), true},
+		{"入れ子の複合形状", shapeEntity(0, 0, compoundShape(circleRow(3), circleRow(3), compoundShape(square(2), circleRow(5)))), shapeEntity(40, 0, square(1)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shapesOverlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("shapesOverlap(a, b) = %v, want %v", got, tt.want)
+			}
+			if got := shapesOverlap(tt.b, tt.a); got != tt.want {
+				t.Errorf("shapesOverlap(b, a) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	cw := []Vec2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	ccw := []Vec2{{0, 0}, {0, 10}, {10, 10}, {10, 0}}
+	tests := []struct {
+		name string
+		p    Vec2
+		want bool
+	}{
+		{"内側", Vec2{5, 5}, true},
+		{"辺上", Vec2{10, 5}, true},
+		{"頂点", Vec2{0, 0}, true},
+		{"外側", Vec2{11, 5}, false},
+		{"辺の延長線上の外側", Vec2{15, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pointInPolygon(tt.p, cw); got != tt.want {
+				t.Errorf("時計回り: got %v, want %v", got, tt.want)
+			}
+			if got := pointInPolygon(tt.p, ccw); got != tt.want {
+				t.Errorf("反時計回り: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentDistances(t *testing.T) {
+	tests := []struct {
+		name           string
+		a1, a2, b1, b2 Vec2
+		want           float64
+	}{
+		{"交差", Vec2{0, 0}, Vec2{10, 10}, Vec2{0, 10}, Vec2{10, 0}, 0},
+		{"平行", Vec2{0, 0}, Vec2{10, 0}, Vec2{0, 3}, Vec2{10, 3}, 9},
+		{"T字で離れる", Vec2{0, 0}, Vec2{10, 0}, Vec2{5, 2}, Vec2{5, 8}, 4},
+		{"同一直線上で離れる", Vec2{0, 0}, Vec2{10, 0}, Vec2{13, 0}, Vec2{20, 0}, 9},
+		{"端点が点", Vec2{0, 0}, Vec2{0, 0}, Vec2{3, 4}, Vec2{3, 4}, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := segmentSegmentDistSq(tt.a1, tt.a2, tt.b1, tt.b2); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("segmentSegmentDistSq = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 点と線分: 線分の内側への射影と、端点へのクランプ
+	if got := pointSegmentDistSq(Vec2{5, 5}, Vec2{0, 0}, Vec2{10, 0}); got != 25 {
+		t.Errorf("射影: got %v", got)
+	}
+	if got := pointSegmentDistSq(Vec2{-3, 4}, Vec2{0, 0}, Vec2{10, 0}); got != 25 {
+		t.Errorf("端点へのクランプ: got %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+/**
+ * @file spatial.go
+ * @description 衝突判定のブロードフェーズ（一様グリッドによる空間分割）
+ *
+ * 概要:
+ * - 画面を一定サイズのセルに分割し、エンティティを重なるセルに登録
+ * - 毎ティック再構築し、近くのセルにいるエンティティだけを衝突候補とする
+ * - 候補に対する詳細判定（ナローフェーズ）は checkCollision で行う
+ */
+
+package main
+
+import "math"
+
+const (
+	// ワールドの幅（ピクセル）
+	worldWidth = 800
+	// ワールドの高さ（ピクセル）
+	worldHeight = 600
+	// グリッドのセルサイズ（ピクセル）
+	gridCellSize = 64
+)
+
+/**
+ * 一様グリッド構造体
+ * @property {float64} cellSize - セルの一辺の長さ
+ * @property {int} cols - 列数
+ * @property {int} rows - 行数
+ * @property {[][]*Entity} cells - セルごとのエンティティリスト
+ * @property {uint32} stamp - クエリごとの重複排除用スタンプ
+ */
+type SpatialGrid struct {
+	cellSize float64
+	cols     int
+	rows     int
+	cells    [][]*Entity
+	stamp    uint32
+}
+
+/**
+ * 新しいグリッドを作成する
+ * @param {float64} width - ワールドの幅
+ * @param {float64} height - ワールドの高さ
+ * @param {float64} cellSize - セルの一辺の長さ
+ * @returns {*SpatialGrid} - 作成されたグリッド
+ */
+func newSpatialGrid(width, height, cellSize float64) *SpatialGrid {
+	cols := int(math.Ceil(width / cellSize))
+	rows := int(math.Ceil(height / cellSize))
+	return &SpatialGrid{
+		cellSize: cellSize,
+		cols:     cols,
+		rows:     rows,
+		cells:    make([][]*Entity, cols*rows),
+	}
+}
+
+/**
+ * 全セルを空にする（確保済みの容量は再利用）
+ */
+func (g *SpatialGrid) Clear() {
+	for i := range g.cells {
+		g.cells[i] = g.cells[i][:0]
+	}
+}
+
+/**
+ * エンティティの矩形が重なるセル範囲を求める（画面外はクランプ）
+ * @param {*Entity} e - エンティティ
+ * @returns {int, int, int, int} - 列の最小・最大、行の最小・最大
+ */
+func (g *SpatialGrid) cellRange(e *Entity) (int, int, int, int) {
+	clamp := func(v, max int) int {
+		if v < 0 {
+			return 0
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+	minCol := clamp(int(math.Floor(e.X/g.cellSize)), g.cols-1)
+	maxCol := clamp(int(math.Floor((e.X+float64(e.Width))/g.cellSize)), g.cols-1)
+	minRow := clamp(int(math.Floor(e.Y/g.cellSize)), g.rows-1)
+	maxRow := clamp(int(math.Floor((e.Y+float64(e.Height))/g.cellSize)), g.rows-1)
+	return minCol, maxCol, minRow, maxRow
+}
+
+/**
+ * エンティティを重なる全セルに登録する
+ * @param {*Entity} e - エンティティ
+ */
+func (g *SpatialGrid) Insert(e *Entity) {
+	minCol, maxCol, minRow, maxRow := g.cellRange(e)
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			i := row*g.cols + col
+			g.cells[i] = append(g.cells[i], e)
+		}
+	}
+}
+
+/**
+ * 指定エンティティと同じセルにいる候補を列挙する
+ * 複数セルにまたがる候補も1回だけ訪問する
+ * @param {*Entity} e - 判定元のエンティティ
+ * @param {func(*Entity) bool} visit - 候補ごとのコールバック（falseを返すと打ち切り）
+ */
+func (g *SpatialGrid) Query(e *Entity, visit func(candidate *Entity) bool) {
+	g.stamp++
+	minCol, maxCol, minRow, maxRow := g.cellRange(e)
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			for _, candidate := range g.cells[row*g.cols+col] {
+				if candidate.queryStamp == g.stamp {
+					continue
+				}
+				candidate.queryStamp = g.stamp
+				if !visit(candidate) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/**
+ * @file spatial_test.go
+ * @description ブロードフェーズ（一様グリッド）のテストとベンチマーク
+ *
+ * 概要:
+ * - Query が矩形の重なる候補を漏れなく、1回ずつ返すことを確認する
+ * - 弾2,000発のティック時間を、グリッドと総当たり（セル1つのグリッド）で比較する
+ */
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+/**
+ * テスト用のエンティティを作成する（グリッドに登録するエンティティは衝突コンポーネントを持つ）
+ * @param {float64} x - X座標
+ * @param {float64} y - Y座標
+ * @param {int} width - 幅
+ * @param {int} height - 高さ
+ * @returns {*Entity} - エンティティ
+ */
+func boxEntity(x, y float64, width, height int) *Entity {
+	return &Entity{Transform: transformAt(x, y, width, height), Collider: &Collider{}}
+}
+
+/**
+ * グリッドの検索結果を数える
+ * @param {*SpatialGrid} grid - グリッド
+ * @param {*Entity} e - 判定元のエンティティ
+ * @returns {map[*Entity]int} - 候補ごとの訪問回数
+ */
+func queryCounts(grid *SpatialGrid, e *Entity) map[*Entity]int {
+	counts := make(map[*Entity]int)
+	grid.Query(e, func(candidate *Entity) bool {
+		counts[candidate]++
+		return true
+	})
+	return counts
+}
+
+func TestSpatialGridQuery(t *testing.T) {
+	grid := newSpatialGrid(worldWidth, worldHeight, gridCellSize)
+	wide := boxEntity(50, 50, 300, 200)     // 複数セルにまたがる
+	offLeft := boxEntity(-120, -80, 40, 40) // 画面外（左上のセルにクランプ）
+	offRight := boxEntity(900, 700, 50, 50) // 画面外（右下のセルにクランプ）
+	huge := boxEntity(-50, -50, 1000, 800)  // 画面全体を覆う
+	edge := boxEntity(worldWidth-10, 0, 10, 10)
+	entities := []*Entity{wide, offLeft, offRight, huge, edge}
+	for _, e := range entities {
+		grid.Insert(e)
+	}
+
+	tests := []struct {
+		name  string
+		query *Entity
+		want  []*Entity
+	}{
+		{"複数セルにまたがる候補", boxEntity(300, 200, 10, 10), []*Entity{wide, huge}},
+		{"画面外同士", boxEntity(-100, -60, 10, 10), []*Entity{offLeft, huge}},
+		{"画面外の右下", boxEntity(920, 720, 5, 5), []*Entity{offRight}},
+		{"画面の端", boxEntity(worldWidth-5, 5, 2, 2), []*Entity{edge, huge}},
+		{"全体を覆う検索範囲", boxEntity(-500, -500, 2000, 2000), entities},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := queryCounts(grid, tt.query)
+			for _, want := range tt.want {
+				if counts[want] != 1 {
+					t.Errorf("(%v, %v) の訪問回数が %d", want.X, want.Y, counts[want])
+				}
+			}
+			for e, n := range counts {
+				if n != 1 {
+					t.Errorf("(%v, %v) を %d 回訪問した", e.X, e.Y, n)
+				}
+			}
+		})
+	}
+}
+
+func TestSpatialGridQueryRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	box := func() *Entity {
+		return boxEntity(rng.Float64()*1000-100, rng.Float64()*800-100, 1+rng.Intn(200), 1+rng.Intn(200))
+	}
+	grid := newSpatialGrid(worldWidth, worldHeight, gridCellSize)
+	var entities []*Entity
+	for i := 0; i < 300; i++ {
+		e := box()
+		entities = append(entities, e)
+		grid.Insert(e)
+	}
+	for i := 0; i < 300; i++ {
+		q := box()
+		counts := queryCounts(grid, q)
+		for _, e := range entities {
+			if checkAABB(q, e) && counts[e] != 1 {
+				t.Fatalf("重なる候補 (%v, %v %dx%d) の訪問回数が %d（検索範囲 (%v, %v %dx%d)）",
+					e.X, e.Y, e.Width, e.Height, counts[e], q.X, q.Y, q.Width, q.Height)
+			}
+		}
+		for e, n := range counts {
+			if n != 1 {
+				t.Fatalf("(%v, %v) を %d 回訪問した", e.X, e.Y, n)
+			}
+		}
+	}
+}
+
+func TestSpatialGridQueryStop(t *testing.T) {
+	grid := newSpatialGrid(worldWidth, worldHeight, gridCellSize)
+	for i := 0; i < 10; i++ {
+		grid.Insert(boxEntity(10, 10, 5, 5))
+	}
+	visited := 0
+	grid.Query(boxEntity(0, 0, 20, 20), func(*Entity) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Fatalf("false を返しても検索が続いた: %d", visited)
+	}
+}
+
+/**
+ * 弾2,000発と敵100体が画面に止まっているルームを作成する（命中しない配置なので毎ティック同じ状態になる）
+ * @param {float64} cellSize - グリッドのセルサイズ（画面より大きければ総当たり）
+ * @returns {*GameRoom} - ルーム
+ */
+func benchBulletRoom(cellSize float64) *GameRoom {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	gameRoom.grids = make(map[CollisionLayer]*SpatialGrid)
+	targets := targetedLayers()
+	for layer := layerPlayer; layer <= layerBoss; layer <<= 1 {
+		if targets&layer != 0 {
+			gameRoom.grids[layer] = newSpatialGrid(worldWidth, worldHeight, cellSize)
+		}
+	}
+
+	p := &Player{
+		Entity: Entity{
+			Type:      "player",
+			Transform: transformAt(worldWidth/2, worldHeight-40, 30, 30),
+			Velocity:  &Velocity{Confined: true},
+			Collider:  &Collider{Shape: playerHurtbox},
+			Health:    &Health{Current: maxPlayerHealth, Max: maxPlayerHealth},
+			Weapon:    &Weapon{Name: defaultWeapon, FirePower: 1},
+		},
+		ID:      "bench",
+		Effects: make(map[string]int),
+	}
+	setLayer(&p.Entity, layerPlayer)
+	gameRoom.Players[p.ID] = p
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		e, c := gameRoom.entities.Spawn()
+		e.Type = "enemy"
+		e.Transform = transformAt(float64(i%25)*32, float64(i/25)*32, 30, 30)
+		e.Velocity = c.Velocity(Velocity{})
+		e.Collider = c.Collider(Collider{Damage: 10})
+		e.Health = c.Health(Health{Current: 1, Max: 1})
+		setLayer(e, layerEnemy)
+		gameRoom.Enemies[e.ID] = e
+	}
+	for i := 0; i < 2000; i++ {
+		layer := layerPlayerShot
+		if i%2 == 1 {
+			layer = layerEnemyShot
+		}
+		b, c := gameRoom.entities.Spawn()
+		b.Type = "bullet"
+		b.Transform = transformAt(rng.Float64()*(worldWidth-5), 160+rng.Float64()*300, 5, 10)
+		b.Velocity = c.Velocity(Velocity{})
+		b.Collider = c.Collider(Collider{Damage: 1})
+		setLayer(b, layer)
+		gameRoom.Bullets[b.ID] = b
+	}
+	return gameRoom
+}
+
+/**
+ * 弾2,000発のティックを計測する
+ * @param {*testing.B} b - ベンチマーク
+ * @param {float64} cellSize - グリッドのセルサイズ
+ */
+func benchmarkBulletTick(b *testing.B, cellSize float64) {
+	gameRoom := benchBulletRoom(cellSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		updateGame(gameRoom)
+	}
+	b.StopTimer()
+	if len(gameRoom.Bullets) != 2000 || len(gameRoom.Enemies) != 100 {
+		b.Fatalf("状態が変わった: 弾 %d / 敵 %d", len(gameRoom.Bullets), len(gameRoom.Enemies))
+	}
+}
+
+func BenchmarkUpdateGame2000Bullets(b *testing.B) {
+	b.Run("grid", func(b *testing.B) {
+		benchmarkBulletTick(b, gridCellSize)
+	})
+	b.Run("bruteforce", func(b *testing.B) {
+		benchmarkBulletTick(b, worldWidth) // セルが1つなら全候補を調べる
+	})
+}
@@ -0,0 +1,170 @@
+/**
+ * @file sweep.go
+ * @description 高速な弾の連続衝突判定（CCD: Continuous Collision Detection）
+ *
+ * 概要:
+ * - 一定以上の速さの弾を「高速」として扱う
+ * - 高速な弾は前ティックの位置から現在位置までの掃引形状で判定し、すり抜けを防ぐ
+ *   - 矩形の弾: 移動前後の矩形の凸包（掃引AABB）
+ *   - 円形の弾: 移動前後の中心を結ぶカプセル（線分＋半径）
+ * - 経路上の複数の相手に当たる場合は、移動前の位置から先に届く相手から命中させる
+ */
+
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// この速さ（ピクセル／ティック）以上の弾は連続衝突判定を行う
+const fastSpeedThreshold = 8.0
+
+/**
+ * 弾の初期化
+ * 速度に応じて高速フラグを設定する
+ * @param {*Entity} e - 弾
+ */
+func initProjectile(e *Entity) {
+	e.Fast = math.Hypot(e.VelocityX, e.VelocityY) >= fastSpeedThreshold
+}
+
+/**
+ * 弾の当たり判定
+ * 高速な弾は掃引形状で、それ以外は現在位置で判定する
+ * @param {*Entity} b - 弾
+ * @param {float64} prevX - 移動前のX座標
+ * @param {float64} prevY - 移動前のY座標
+ * @param {*Entity} target - 判定相手
+ * @returns {bool} - 衝突している場合true
+ */
+func bulletHits(b *Entity, prevX, prevY float64, target *Entity) bool {
+	if b.Fast {
+		return sweptCollision(b, prevX, prevY, target)
+	}
+	return checkCollision(b, target)
+}
+
+/**
+ * 弾が相手に届く時刻を求める（移動量に対する割合、矩形同士の掃引で判定する）
+ * 同じティックに経路上の複数の相手に当たるとき、手前の相手から処理するために使う
+ * @param {*Entity} b - 弾
+ * @param {float64} prevX - 移動前のX座標
+ * @param {float64} prevY - 移動前のY座標
+ * @param {*Entity} target - 相手
+ * @returns {float64} - 0（移動前から重なっている）〜1（移動後の位置で重なる）
+ */
+func sweepEntryTime(b *Entity, prevX, prevY float64, target *Entity) float64 {
+	entry := func(prev, delta, size, targetMin, targetSize float64) float64 {
+		switch {
+		case delta > 0:
+			return (targetMin - (prev + size)) / delta
+		case delta < 0:
+			return (targetMin + targetSize - prev) / delta
+		}
+		return 0 // この軸では動かない（経路上で重なるなら常に重なっている）
+	}
+	tx := entry(prevX, b.X-prevX, float64(b.Width), target.X, float64(target.Width))
+	ty := entry(prevY, b.Y-prevY, float64(b.Height), target.Y, float64(target.Height))
+	return clampFloat(math.Max(tx, ty), 0, 1)
+}
+
+/**
+ * 移動前後の位置を包む矩形を求める（ブロードフェーズの検索範囲に使用）
+ * @param {*Entity} e - 弾
+ * @param {float64} prevX - 移動前のX座標
+ * @param {float64} prevY - 移動前のY座標
+ * @returns {Entity} - 掃引範囲の矩形（位置とサイズのみ設定）
+ */
+func sweptBounds(e *Entity, prevX, prevY float64) Entity {
+	minX := math.Min(prevX, e.X)
+	minY := math.Min(prevY, e.Y)
+	maxX := math.Max(prevX, e.X) + float64(e.Width)
+	maxY := math.Max(prevY, e.Y) + float64(e.Height)
+	return Entity{Transform: Transform{
+		X:      minX,
+		Y:      minY,
+		Width:  int(math.Ceil(maxX - minX)),
+		Height: int(math.Ceil(maxY - minY)),
+	}}
+}
+
+/**
+ * 掃引形状による連続衝突判定
+ * @param {*Entity} e - 弾
+ * @param {float64} prevX - 移動前のX座標
+ * @param {float64} prevY - 移動前のY座標
+ * @param {*Entity} target - 判定相手
+ * @returns {bool} - 移動経路上で衝突していればtrue
+ */
+func sweptCollision(e *Entity, prevX, prevY float64, target *Entity) bool {
+	bounds := sweptBounds(e, prevX, prevY)
+	if !checkAABB(&bounds, target) {
+		return false
+	}
+
+	var sweep primitive
+	if e.Shape != nil && e.Shape.Kind == shapeCircle {
+		// 円は中心の軌跡をカプセルにする
+		c := e.Shape.A
+		sweep = primitive{
+			a:      Vec2{prevX + c.X, prevY + c.Y},
+			b:      Vec2{e.X + c.X, e.Y + c.Y},
+			radius: e.Shape.Radius,
+		}
+	} else {
+		// 矩形は移動前後の矩形の凸包にする
+		sweep = primitive{polygon: true, points: sweptHull(e, prevX, prevY)}
+	}
+
+	var buf [4]primitive
+	for _, p := range worldPrimitives(target, buf[:0]) {
+		if primitivesOverlap(&sweep, &p) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 移動前後の矩形8頂点の凸包を求める（Andrew's monotone chain）
+ * @param {*Entity} e - 弾
+ * @param {float64} prevX - 移動前のX座標
+ * @param {float64} prevY - 移動前のY座標
+ * @returns {[]Vec2} - 凸包の頂点（反時計回り）
+ */
+func sweptHull(e *Entity, prevX, prevY float64) []Vec2 {
+	w, h := float64(e.Width), float64(e.Height)
+	points := []Vec2{
+		{prevX, prevY}, {prevX + w, prevY}, {prevX + w, prevY + h}, {prevX, prevY + h},
+		{e.X, e.Y}, {e.X + w, e.Y}, {e.X + w, e.Y + h}, {e.X, e.Y + h},
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X != points[j].X {
+			return points[i].X < points[j].X
+		}
+		return points[i].Y < points[j].Y
+	})
+
+	cross := func(o, a, b Vec2) float64 {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+	hull := make([]Vec2, 0, len(points)*2)
+	// 下側
+	for _, p := range points {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	// 上側
+	lower := len(hull) + 1
+	for i := len(points) - 2; i >= 0; i-- {
+		p := points[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
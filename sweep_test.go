@@ -0,0 +1,169 @@
+/**
+ * @file sweep_test.go
+ * @description 高速な弾の連続衝突判定のテスト
+ *
+ * 概要:
+ * - 1ティックで敵を通り抜ける弾が、掃引形状では命中し、現在位置だけの判定では外れることを確認する
+ * - ゲームループの1ティックでも、レールの速さの弾が敵に命中することを確認する
+ * - 経路上に複数の敵がいるとき、貫通しない弾は手前の敵に命中することを確認する
+ */
+
+package main
+
+import "testing"
+
+// 敵の位置（スカウトの三角形は頂点 (110, 100)、底辺 (100, 120)〜(120, 120)）
+const sweepEnemyX, sweepEnemyY = 100.0, 100.0
+
+/**
+ * 移動済みの弾を作成する
+ * 三角形の左側の列（x=101〜103）では敵の本体が y≥114 にしかないため、
+ * 移動前は敵の下、移動後は敵の上にあり、どちらの位置でも敵と重ならない
+ * @param {float64} x - 移動後のX座標
+ * @param {float64} vy - Y方向の速度（移動前の位置は y-vy）
+ * @returns {*Entity} - 弾
+ */
+func movedBullet(x, vy float64) *Entity {
+	b := &Entity{
+		Type:      "bullet",
+		Transform: transformAt(x, sweepEnemyY+1, 2, 2),
+		Velocity:  &Velocity{VelocityY: vy},
+		Collider:  &Collider{Damage: 3},
+	}
+	initProjectile(b)
+	b.prevX, b.prevY = b.X, b.Y-vy
+	return b
+}
+
+/**
+ * 止まっているスカウトを作成する
+ * @returns {*Entity} - 敵
+ */
+func sweepScout() *Entity {
+	scout := enemyArchetype("scout")
+	return &Entity{
+		Type:      "enemy",
+		Transform: transformAt(sweepEnemyX, sweepEnemyY, scout.Size, scout.Size),
+		Velocity:  &Velocity{},
+		Collider:  &Collider{Shape: enemyShape(scout)},
+		Health:    &Health{Current: scout.Health, Max: scout.Health},
+	}
+}
+
+func TestBulletHitsSweep(t *testing.T) {
+	tests := []struct {
+		name      string
+		bullet    *Entity
+		want      bool
+		wantPoint bool // 現在位置だけで判定した結果
+	}{
+		{"レールの速さで通り抜ける", movedBullet(101, -20), true, false},
+		{"低速な弾は現在位置で判定", movedBullet(101, -6), false, false},
+		{"敵の横を通る", movedBullet(125, -20), false, false},
+		{"移動後も敵と重なる", movedBullet(109, -20), true, true},
+	}
+	enemy := sweepScout()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.bullet
+			if got := bulletHits(b, b.prevX, b.prevY, enemy); got != tt.want {
+				t.Errorf("bulletHits = %v, want %v", got, tt.want)
+			}
+			if got := checkCollision(b, enemy); got != tt.wantPoint {
+				t.Errorf("checkCollision = %v, want %v", got, tt.wantPoint)
+			}
+		})
+	}
+}
+
+func TestRailBulletHitsInOneTick(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := &Player{
+		Entity: Entity{
+			Type:      "player",
+			Transform: transformAt(worldWidth/2, worldHeight-40, 30, 30),
+			Velocity:  &Velocity{Confined: true},
+			Collider:  &Collider{Shape: playerHurtbox},
+			Health:    &Health{Current: maxPlayerHealth, Max: maxPlayerHealth},
+			Weapon:    &Weapon{Name: weaponRail, FirePower: 1},
+		},
+		ID:      "sweep",
+		Effects: make(map[string]int),
+	}
+	setLayer(&p.Entity, layerPlayer)
+	gameRoom.Players[p.ID] = p
+
+	scout := enemyArchetype("scout")
+	e, c := gameRoom.entities.Spawn()
+	e.Type = "enemy"
+	e.Transform = transformAt(sweepEnemyX, sweepEnemyY, scout.Size, scout.Size)
+	e.Velocity = c.Velocity(Velocity{})
+	e.Collider = c.Collider(Collider{Shape: enemyShape(scout)})
+	e.Health = c.Health(Health{Current: scout.Health, Max: scout.Health})
+	setLayer(e, layerEnemy)
+	gameRoom.Enemies[e.ID] = e
+
+	// 移動前は敵の下、1ティック後（y=101）は三角形の上を抜けている
+	b, bc := gameRoom.entities.Spawn()
+	b.Type = "bullet"
+	b.Kind = weaponRail
+	b.Transform = transformAt(101, sweepEnemyY+21, 2, 2)
+	b.Velocity = bc.Velocity(Velocity{VelocityY: -20})
+	b.Collider = bc.Collider(Collider{Damage: 3, Pierce: 4, Owner: p.ID})
+	b.Lifetime = bc.Lifetime(Lifetime{})
+	setLayer(b, layerPlayerShot)
+	initProjectile(b)
+	gameRoom.Bullets[b.ID] = b
+
+	updateGame(gameRoom)
+
+	if b.Y != sweepEnemyY+1 {
+		t.Fatalf("弾が移動していない: y=%v", b.Y)
+	}
+	if checkCollision(b, e) {
+		t.Fatal("移動後の弾が敵と重なっている（すり抜けの検証にならない）")
+	}
+	if _, ok := gameRoom.Enemies[e.ID]; ok && e.Health.Current >= scout.Health {
+		t.Fatal("1ティックで敵を通り抜けた弾が命中しなかった")
+	}
+}
+
+func TestFastBulletHitsNearestFirst(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	testPlayer(gameRoom, "p1", 100, 550)
+
+	// 奥の敵を先に生成する（生成順なら奥の敵が先に判定される）
+	spawn := func(y float64) *Entity {
+		e, c := gameRoom.entities.Spawn()
+		e.Type = "enemy"
+		e.Transform = transformAt(400, y, 20, 20)
+		e.Velocity = c.Velocity(Velocity{})
+		e.Collider = c.Collider(Collider{Damage: 10})
+		e.Health = c.Health(Health{Current: 1, Max: 1})
+		setLayer(e, layerEnemy)
+		gameRoom.Enemies[e.ID] = e
+		return e
+	}
+	far := spawn(350)
+	near := spawn(375)
+
+	// 1ティックで y=400 から y=340 へ抜け、両方の敵を通る貫通しない弾
+	b, c := gameRoom.entities.Spawn()
+	b.Type = "bullet"
+	b.Transform = transformAt(408, 340, 4, 4)
+	b.Velocity = c.Velocity(Velocity{VelocityY: -60})
+	b.Collider = c.Collider(Collider{Damage: 1})
+	setLayer(b, layerPlayerShot)
+	initProjectile(b)
+	b.prevX, b.prevY = b.X, 400
+	gameRoom.Bullets[b.ID] = b
+
+	resolveCollisions(gameRoom)
+
+	if near.Health.Current > 0 {
+		t.Error("手前の敵に命中していない")
+	}
+	if far.Health.Current <= 0 {
+		t.Error("手前の敵を通り抜けて奥の敵に命中した")
+	}
+}
@@ -0,0 +1,199 @@
+/**
+ * @file systems.go
+ * @description ゲームロジックのシステムと実行順序
+ *
+ * 概要:
+ * - 各システムはコンポーネントの有無で対象のエンティティを選び、1ティック分の処理を行う
+ * - updateGame は systems を登録順に実行する
+ * - 新しい仕組みは updateGame に分岐を足すのではなく、システムとして追加する
+ */
+
+package main
+
+import "math"
+
+/**
+ * システム構造体
+ * @property {string} Name - システム名
+ * @property {func(*GameRoom)} Update - 1ティック分の処理（ロック済みで呼ばれる）
+ */
+type System struct {
+	Name   string
+	Update func(gameRoom *GameRoom)
+}
+
+// システムの実行順序
+var systems = []System{
+	{Name: "effects", Update: effectSystem},
+	{Name: "respawn", Update: respawnSystem},
+	{Name: "damage", Update: damageSystem},
+	{Name: "score", Update: scoreSystem},
+	{Name: "weapon", Update: weaponSystem},
+	{Name: "ai", Update: aiSystem},
+	{Name: "movement", Update: movementSystem},
+	{Name: "versus", Update: versusSystem},
+	{Name: "collision", Update: collisionSystem},
+	{Name: "graze", Update: grazeSystem},
+	{Name: "lifetime", Update: lifetimeSystem},
+	{Name: "pings", Update: pingSystem},
+	{Name: "waves", Update: waveSystem},
+	{Name: "daily", Update: dailySystem},
+	{Name: "match", Update: matchSystem},
+}
+
+/**
+ * 効果システム: プレイヤーの時間制限付き効果を進め、移動速度に反映する
+ */
+func effectSystem(gameRoom *GameRoom) {
+	for _, p := range gameRoom.Players {
+		tickEffects(p)
+
+		// スピードアップ中は移動量を増やす
+		p.Scale = 0
+		if hasEffect(p, itemSpeed) {
+			p.Scale = speedBoostMultiplier
+		}
+	}
+}
+
+/**
+ * 武器システム: 武器を持つエンティティのチャージを進める
+ */
+func weaponSystem(gameRoom *GameRoom) {
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.Weapon != nil {
+			tickWeapon(e.Weapon)
+		}
+	})
+}
+
+/**
+ * AIシステム: 自律行動を持つエンティティの行動処理を呼ぶ
+ * 行動中に弾が追加されるため、対象を先に集める。攻撃の乱数を引く順序がそろうよう生成順に呼ぶ
+ */
+func aiSystem(gameRoom *GameRoom) {
+	var actors []*Entity
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.AI != nil && e.AI.Update != nil {
+			actors = append(actors, e)
+		}
+	})
+	sortBySpawn(actors)
+	for _, e := range actors {
+		e.AI.Update(gameRoom, e)
+	}
+}
+
+/**
+ * 移動システム: 速度を持つエンティティを移動する
+ */
+func movementSystem(gameRoom *GameRoom) {
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.Velocity != nil {
+			moveEntity(e)
+		}
+	})
+}
+
+/**
+ * 衝突システム: レイヤーとマスクに従って衝突処理を行う
+ */
+func collisionSystem(gameRoom *GameRoom) {
+	resolveCollisions(gameRoom)
+}
+
+/**
+ * 寿命システム: 寿命が尽きた、または画面外に出たエンティティを取り除く
+ * 高速な弾も画面外に出る直前までの経路は衝突システムで判定済み
+ */
+func lifetimeSystem(gameRoom *GameRoom) {
+	forEachEntity(gameRoom, func(e *Entity) {
+		if e.Lifetime == nil {
+			return
+		}
+		if e.Ticks > 0 {
+			e.Ticks--
+			if e.Ticks == 0 {
+				despawn(gameRoom, e)
+				return
+			}
+		}
+		if e.X+float64(e.Width) < 0 || e.X > worldWidth || e.Y+float64(e.Height) < 0 || e.Y > worldHeight {
+			despawn(gameRoom, e)
+		}
+	})
+}
+
+/**
+ * エンティティを速度の分だけ移動する
+ * 連続衝突判定のため移動前の位置を記録する。ノックバックを加え、画面内に留めるエンティティはクランプする
+ * @param {*Entity} e - エンティティ
+ */
+func moveEntity(e *Entity) {
+	scale := 1.0
+	if e.Scale > 0 {
+		scale = e.Scale
+	}
+	e.prevX, e.prevY = e.X, e.Y
+	e.X += e.VelocityX*scale + e.KnockbackX
+	e.Y += e.VelocityY*scale + e.KnockbackY
+
+	// ノックバックは徐々に弱まる
+	if e.KnockbackX != 0 || e.KnockbackY != 0 {
+		e.KnockbackX *= knockbackDecay
+		e.KnockbackY *= knockbackDecay
+		if math.Abs(e.KnockbackX) < 0.1 && math.Abs(e.KnockbackY) < 0.1 {
+			e.KnockbackX, e.KnockbackY = 0, 0
+		}
+	}
+
+	if e.Confined {
+		e.X = clampFloat(e.X, 0, worldWidth-float64(e.Width))
+		e.Y = clampFloat(e.Y, 0, worldHeight-float64(e.Height))
+	}
+}
+
+/**
+ * 値を範囲内に収める
+ * @param {float64} v - 値
+ * @param {float64} lo - 下限
+ * @param {float64} hi - 上限
+ * @returns {float64} - 範囲内に収めた値
+ */
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+/**
+ * 雑魚敵のAI: 難易度と人数で補正した確率で真下に弾を撃つ
+ */
+func enemyAI(gameRoom *GameRoom, enemy *Entity) {
+	if gameRoom.combatRng.Float64() >= enemyFireChance(gameRoom) {
+		return
+	}
+	eb, c := gameRoom.entities.Spawn()
+	eb.Type = "enemyBullet"
+	eb.Transform = transformAt(enemy.X+float64(enemy.Width)/2, enemy.Y+float64(enemy.Height), 5, 5)
+	eb.Velocity = c.Velocity(Velocity{VelocityX: 0, VelocityY: 3})
+	eb.Collider = c.Collider(Collider{Damage: 15})
+	eb.Lifetime = c.Lifetime(Lifetime{})
+	setLayer(eb, layerEnemyShot)
+	initProjectile(eb)
+	gameRoom.Bullets[eb.ID] = eb
+}
+
+/**
+ * ボスのAI: 画面端で反転し、ボス定義のパターンで攻撃する
+ */
+func bossAI(gameRoom *GameRoom, boss *Entity) {
+	if boss.X <= 0 || boss.X+float64(boss.Width) >= worldWidth {
+		boss.VelocityX *= -1
+	}
+	bossAttack(gameRoom, boss)
+}
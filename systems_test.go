@@ -0,0 +1,131 @@
+/**
+ * @file systems_test.go
+ * @description コンポーネントとシステムのテスト
+ *
+ * 概要:
+ * - システムの実行順序（行動→移動→衝突→寿命）が保たれていることを確認する
+ * - 移動システムが速度を持つエンティティだけを動かし、プレイヤーを画面内に留めることを確認する
+ * - 寿命システムが寿命切れ・画面外のエンティティを取り除くことを確認する
+ * - プレイ中でないルームは更新されないことを確認する
+ * - コンポーネントのフィールドがJSONで平坦に出力されることを確認する
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSystemOrder(t *testing.T) {
+	index := make(map[string]int, len(systems))
+	for i, s := range systems {
+		if _, dup := index[s.Name]; dup {
+			t.Fatalf("システム %q が重複している", s.Name)
+		}
+		if s.Update == nil {
+			t.Fatalf("システム %q に処理がない", s.Name)
+		}
+		index[s.Name] = i
+	}
+	for _, pair := range [][2]string{
+		{"effects", "movement"},
+		{"respawn", "collision"},
+		{"weapon", "ai"},
+		{"ai", "movement"},
+		{"movement", "collision"},
+		{"collision", "lifetime"},
+	} {
+		before, ok1 := index[pair[0]]
+		after, ok2 := index[pair[1]]
+		if !ok1 || !ok2 || before >= after {
+			t.Errorf("%s が %s より先に実行されない", pair[0], pair[1])
+		}
+	}
+}
+
+func TestMovementSystem(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", worldWidth-40, 100)
+	p.VelocityX = 20
+	enemy := stillEnemy(gameRoom, "grunt", 100, 100)
+	enemy.VelocityX, enemy.VelocityY = 1, 2
+	fixed := stillEnemy(gameRoom, "grunt", 300, 100)
+	fixed.Velocity = nil
+
+	movementSystem(gameRoom)
+
+	if enemy.X != 101 || enemy.Y != 102 || enemy.prevX != 100 || enemy.prevY != 100 {
+		t.Errorf("敵の移動 (%v, %v) 前 (%v, %v)", enemy.X, enemy.Y, enemy.prevX, enemy.prevY)
+	}
+	if fixed.X != 300 || fixed.Y != 100 {
+		t.Errorf("速度のないエンティティが動いた: (%v, %v)", fixed.X, fixed.Y)
+	}
+	if want := worldWidth - float64(p.Width); p.X != want {
+		t.Errorf("プレイヤーが画面外へ出た: x=%v, want %v", p.X, want)
+	}
+}
+
+func TestLifetimeSystem(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	timed, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, 0, 5, 10, 1, 0)
+	timed.Ticks = 2
+	offscreen, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, 0, 5, 10, 1, 0)
+	offscreen.Y = -20
+	kept, _ := spawnPlayerBullet(gameRoom, p, defaultWeapon, 0, 0, 0, 5, 10, 1, 0)
+	p.Y = worldHeight + 50 // 寿命を持たないプレイヤーは取り除かれない
+
+	lifetimeSystem(gameRoom)
+	if gameRoom.Bullets[timed.ID] != timed || gameRoom.Bullets[offscreen.ID] != nil {
+		t.Fatal("1ティック目: 画面外の弾だけが取り除かれるはず")
+	}
+	lifetimeSystem(gameRoom)
+	if gameRoom.Bullets[timed.ID] != nil {
+		t.Error("寿命が尽きた弾が残っている")
+	}
+	if gameRoom.Bullets[kept.ID] != kept || gameRoom.Players[p.ID] != p {
+		t.Error("寿命の残るエンティティが取り除かれた")
+	}
+}
+
+func TestUpdateGameSkipsStoppedRoom(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	enemy := stillEnemy(gameRoom, "grunt", 100, 100)
+	enemy.VelocityY = 2
+
+	gameRoom.GameState = "gameover"
+	updateGame(gameRoom)
+	if enemy.Y != 100 {
+		t.Fatalf("ゲームオーバーのルームで敵が動いた: y=%v", enemy.Y)
+	}
+	gameRoom.GameState = "playing"
+	updateGame(gameRoom)
+	if enemy.Y != 102 {
+		t.Fatalf("プレイ中のルームで敵が動かない: y=%v", enemy.Y)
+	}
+}
+
+func TestComponentsFlattenInJSON(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	enemy := stillEnemy(gameRoom, "tank", 100, 50)
+
+	data, err := json.Marshal(enemy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"x", "y", "width", "velocityY", "health", "maxHealth", "damage"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("JSONに %q がない: %s", key, data)
+		}
+	}
+	for _, key := range []string{"Transform", "Collider", "Health", "Shape"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("コンポーネント %q が入れ子で出力された", key)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+/**
+ * @file versus.go
+ * @description チーム対戦（2対2）
+ *
+ * 概要:
+ * - プレイヤーを赤・青の2チームに分け、画面の左半分・右半分をそれぞれの自陣とする（自陣から出られない）
+ * - チームの色はプレイヤーの色より優先する（対戦中は色を変更できない）
+ * - 敵は両陣地に出現し、自陣の下端を抜けた敵はそのチームの体力を減らす。味方が倒れても体力が減る
+ * - 自陣のアイテムは取得するか、撃って壊して相手の陣地に敵を送り込むかを選べる
+ * - スコアはチームごとにも合計する
+ * - ラウンド制: 体力が0になったチームが出るか、制限時間が過ぎると、残り体力の多いチームがラウンドに勝利
+ * - 規定のラウンド数を先取したチームの勝利（最大ラウンド数に達したら勝利数で決め、同数なら引き分け）
+ */
+
+package main
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// チーム名
+const (
+	teamRed  = "red"
+	teamBlue = "blue"
+)
+
+const (
+	// ラウンド開始時のチームの体力
+	teamMaxHealth = 100
+	// 味方が倒れたときに減るチームの体力
+	teamDownPenalty = 10
+	// ラウンドの制限時間（ティック）
+	roundDurationTicks = 90 * 60
+	// 勝利に必要なラウンド数
+	roundsToWin = 2
+	// 最大ラウンド数（引き分けが続いた場合）
+	maxRounds = 5
+	// 陣地の幅（左半分が赤、右半分が青）
+	sideWidth = worldWidth / 2
+)
+
+var errColorTeam = errors.New("チーム対戦中は色を変更できません")
+
+/**
+ * チーム構造体
+ * @property {string} Name - チーム名
+ * @property {string} Color - チームの色（所属するプレイヤーの色になる）
+ * @property {int} Health - 現在のラウンドの残り体力
+ * @property {int} Wins - 勝利したラウンド数
+ * @property {int} Score - 所属するプレイヤーが得たスコアの合計
+ */
+type Team struct {
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+	Health int    `json:"health"`
+	Wins   int    `json:"wins"`
+	Score  int    `json:"score"`
+}
+
+/**
+ * チーム対戦のプレイヤーごとの結果構造体
+ * @property {string} PlayerID - プレイヤーID
+ * @property {string} Name - プレイヤー名
+ * @property {string} Team - 所属チーム
+ * @property {int} Score - スコア
+ */
+type VersusPlayerResult struct {
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Team     string `json:"team"`
+	Score    int    `json:"score"`
+}
+
+/**
+ * チーム対戦の結果構造体
+ * @property {string} Winner - 勝利したチーム名（引き分けなら空）
+ * @property {[]VersusPlayerResult} Players - プレイヤーごとの結果
+ */
+type VersusResult struct {
+	Winner  string               `json:"winner"`
+	Players []VersusPlayerResult `json:"players"`
+}
+
+/**
+ * チーム対戦の状態構造体（ゲーム状態に含めて送る）
+ * @property {int} Round - 現在のラウンド番号（1始まり）
+ * @property {int} TimeLeft - ラウンドの残り秒数
+ * @property {int} RoundsToWin - 勝利に必要なラウンド数
+ * @property {[]*Team} Teams - チーム（赤・青の順）
+ * @property {[]string} Rounds - 終了したラウンドの勝利チーム（引き分けは空）
+ * @property {*VersusResult} Results - 対戦結果（対戦中はnil）
+ * @property {int} roundTicks - 現在のラウンドの経過ティック数
+ */
+type VersusState struct {
+	Round       int           `json:"round"`
+	TimeLeft    int           `json:"timeLeft"`
+	RoundsToWin int           `json:"roundsToWin"`
+	Teams       []*Team       `json:"teams"`
+	Rounds      []string      `json:"rounds"`
+	Results     *VersusResult `json:"results"`
+	roundTicks  int
+}
+
+/**
+ * チーム対戦の状態を作成する
+ * @returns {*VersusState} - 1ラウンド目の状態
+ */
+func newVersusState() *VersusState {
+	return &VersusState{
+		Round:       1,
+		TimeLeft:    roundDurationTicks / 60,
+		RoundsToWin: roundsToWin,
+		Teams: []*Team{
+			{Name: teamRed, Color: "#FF4444", Health: teamMaxHealth},
+			{Name: teamBlue, Color: "#3399FF", Health: teamMaxHealth},
+		},
+		Rounds: []string{},
+	}
+}
+
+/**
+ * チームの陣地番号を取得する（赤が0、青が1）
+ * @param {string} team - チーム名
+ * @returns {int} - 陣地番号（チームに属していなければ-1）
+ */
+func teamSide(team string) int {
+	switch team {
+	case teamRed:
+		return 0
+	case teamBlue:
+		return 1
+	}
+	return -1
+}
+
+/**
+ * X座標がどちらの陣地か
+ * @param {float64} x - X座標
+ * @returns {int} - 陣地番号
+ */
+func sideAt(x float64) int {
+	if x < sideWidth {
+		return 0
+	}
+	return 1
+}
+
+/**
+ * プレイヤーの所属チームを取得する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー
+ * @returns {*Team} - 所属チーム（チーム対戦でなければnil）
+ */
+func playerTeam(gameRoom *GameRoom, p *Player) *Team {
+	side := teamSide(p.Team)
+	if gameRoom.versus == nil || side < 0 {
+		return nil
+	}
+	return gameRoom.versus.Teams[side]
+}
+
+/**
+ * プレイヤーを人数の少ないチームに入れ、チームの色と自陣の出現位置にする
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - プレイヤー（ルームに追加する前であること）
+ */
+func joinTeam(gameRoom *GameRoom, p *Player) {
+	counts := make([]int, len(gameRoom.versus.Teams))
+	for _, q := range gameRoom.Players {
+		if side := teamSide(q.Team); side >= 0 {
+			counts[side]++
+		}
+	}
+	team := gameRoom.versus.Teams[0]
+	if counts[1] < counts[0] {
+		team = gameRoom.versus.Teams[1]
+	}
+	p.Team = team.Name
+	p.Color = team.Color
+	moveToTeamSpawn(p)
+}
+
+/**
+ * プレイヤーを自陣の下部の出現位置に移動する
+ * @param {*Player} p - プレイヤー
+ */
+func moveToTeamSpawn(p *Player) {
+	side := max(teamSide(p.Team), 0)
+	p.X = float64(side)*sideWidth + sideWidth/2 - float64(p.Width)/2
+	p.Y = worldHeight - float64(p.Height) - 60
+	p.prevX, p.prevY = p.X, p.Y
+}
+
+/**
+ * 陣地内のランダムなX座標を選ぶ
+ * @param {int} side - 陣地番号
+ * @param {int} width - エンティティの幅
+ * @returns {float64} - X座標
+ */
+func randomSideX(side, width int) float64 {
+	return float64(side)*sideWidth + rand.Float64()*float64(sideWidth-width)
+}
+
+/**
+ * 衝突応答: プレイヤーの弾がアイテムに命中（チーム対戦のみ、マスクで有効になる）
+ * アイテムを壊し、相手の陣地に敵を送り込む
+ */
+func shotHitsItem(gameRoom *GameRoom, b, item *Entity) bool {
+	if !registerHit(gameRoom, b, item) {
+		return true
+	}
+	despawn(gameRoom, item)
+	archetype := pickEnemyArchetype(gameRoom)
+	target := 1 - sideAt(item.X+float64(item.Width)/2)
+	spawnEnemy(gameRoom, archetype, randomSideX(target, archetype.Size))
+	return true
+}
+
+/**
+ * チームの体力を減らす（0未満にはしない）
+ * @param {*Team} team - チーム
+ * @param {int} amount - 減らす量
+ */
+func damageTeam(team *Team, amount int) {
+	team.Health = max(team.Health-amount, 0)
+}
+
+/**
+ * チーム対戦システム: 自陣への閉じ込め、敵の突破、ラウンドの進行を処理する
+ * 移動の直後に実行する（画面外に出た敵が寿命システムで取り除かれる前に突破を判定する）
+ */
+func versusSystem(gameRoom *GameRoom) {
+	vs := gameRoom.versus
+	if vs == nil {
+		return
+	}
+
+	// 自陣から出られない
+	for _, p := range gameRoom.Players {
+		if side := teamSide(p.Team); side >= 0 {
+			p.X = clampFloat(p.X, float64(side)*sideWidth, float64(side+1)*sideWidth-float64(p.Width))
+		}
+	}
+
+	// 下端を抜けた敵はその陣地のチームの体力を減らす
+	for _, e := range gameRoom.Enemies {
+		if e.Y > worldHeight {
+			damageTeam(vs.Teams[sideAt(e.X+float64(e.Width)/2)], e.Damage)
+			despawn(gameRoom, e)
+		}
+	}
+
+	vs.roundTicks++
+	vs.TimeLeft = max((roundDurationTicks-vs.roundTicks+59)/60, 0)
+	if vs.roundTicks >= roundDurationTicks || vs.Teams[0].Health == 0 || vs.Teams[1].Health == 0 {
+		endRound(gameRoom)
+	}
+}
+
+/**
+ * ラウンドを終了する
+ * 残り体力の多いチームをラウンドの勝者とし、勝利数が規定に達するか最大ラウンド数なら対戦を終了する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func endRound(gameRoom *GameRoom) {
+	vs := gameRoom.versus
+	red, blue := vs.Teams[0], vs.Teams[1]
+	var winner *Team
+	switch {
+	case red.Health > blue.Health:
+		winner = red
+	case blue.Health > red.Health:
+		winner = blue
+	}
+
+	if winner != nil {
+		winner.Wins++
+		vs.Rounds = append(vs.Rounds, winner.Name)
+		for _, p := range gameRoom.Players {
+			if p.Team == winner.Name {
+				awardScore(gameRoom, p, scorePoints[scoreRoundWin], scoreRoundWin)
+			}
+		}
+	} else {
+		vs.Rounds = append(vs.Rounds, "")
+	}
+
+	if red.Wins >= roundsToWin || blue.Wins >= roundsToWin || len(vs.Rounds) >= maxRounds {
+		endVersus(gameRoom)
+		return
+	}
+	startRound(gameRoom)
+}
+
+/**
+ * 次のラウンドを始める
+ * チームの体力と場を初期化し、全員を自陣で復帰させる
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func startRound(gameRoom *GameRoom) {
+	vs := gameRoom.versus
+	vs.Round = len(vs.Rounds) + 1
+	vs.roundTicks = 0
+	vs.TimeLeft = roundDurationTicks / 60
+	for _, t := range vs.Teams {
+		t.Health = teamMaxHealth
+	}
+	despawnAll(gameRoom, gameRoom.Enemies)
+	despawnAll(gameRoom, gameRoom.Bullets)
+	despawnAll(gameRoom, gameRoom.Items)
+	for _, p := range gameRoom.Players {
+		p.KnockbackX, p.KnockbackY = 0, 0
+		moveToTeamSpawn(p)
+		revivePlayer(p, p.Health.Max)
+	}
+}
+
+/**
+ * 対戦を終了し、結果を記録する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func endVersus(gameRoom *GameRoom) {
+	vs := gameRoom.versus
+	result := &VersusResult{}
+	red, blue := vs.Teams[0], vs.Teams[1]
+	switch {
+	case red.Wins > blue.Wins:
+		result.Winner = red.Name
+	case blue.Wins > red.Wins:
+		result.Winner = blue.Name
+	}
+	for _, p := range gameRoom.Players {
+		result.Players = append(result.Players, VersusPlayerResult{
+			PlayerID: p.ID,
+			Name:     p.Name,
+			Team:     p.Team,
+			Score:    p.Score,
+		})
+	}
+	vs.Results = result
+
+	gameRoom.GameState = "matchover"
+	submitScores(gameRoom)
+	recordProfileStats(gameRoom)
+}
+
+/**
+ * チーム対戦を最初からやり直す（リスタート時）
+ * チームの所属は変えずに状態を初期化し、全員を自陣に移動する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ */
+func resetVersus(gameRoom *GameRoom) {
+	gameRoom.versus = newVersusState()
+	for _, p := range gameRoom.Players {
+		moveToTeamSpawn(p)
+	}
+}
@@ -0,0 +1,251 @@
+/**
+ * @file weapons.go
+ * @description プレイヤーの武器システム
+ *
+ * 概要:
+ * - 武器種類: 拡散（spread）・レーザー（laser）・ホーミング（homing）・レール（rail）・チャージ（charge）
+ * - 武器レベル（FirePower）ごとの弾数・ダメージ・サイズ
+ * - 武器ごとの連射間隔と弾の挙動（ホーミングの追尾など）
+ * - アイテム取得による武器の切り替え
+ */
+
+package main
+
+import "math"
+
+// 武器種類
+const (
+	weaponSpread = "spread"
+	weaponLaser  = "laser"
+	weaponHoming = "homing"
+	weaponRail   = "rail"
+	weaponCharge = "charge"
+)
+
+// 初期装備の武器
+const defaultWeapon = weaponSpread
+
+const (
+	// ホーミング弾の速さ
+	homingSpeed = 6.0
+	// ホーミング弾の1ティックあたりの旋回率（0〜1）
+	homingTurnRate = 0.15
+	// チャージ武器の最大チャージティック数
+	maxChargeTicks = 90
+)
+
+/**
+ * 武器定義構造体
+ * @property {string} Name - 武器名
+ * @property {int} Cooldown - 連射間隔（ティック数）
+ * @property {int} Damage - 基本ダメージ
+ * @property {func(*GameRoom, *Player, WeaponDefinition)} Fire - 発射処理（ロック済みで呼ばれる）
+ */
+type WeaponDefinition struct {
+	Name     string
+	Cooldown int
+	Damage   int
+	Fire     func(gameRoom *GameRoom, p *Player, def WeaponDefinition)
+}
+
+// 武器レジストリ（キー：武器名）
+var weapons = map[string]WeaponDefinition{
+	weaponSpread: {Name: weaponSpread, Cooldown: 8, Damage: 1, Fire: fireSpreadShot},
+	weaponLaser:  {Name: weaponLaser, Cooldown: 4, Damage: 1, Fire: fireLaser},
+	weaponHoming: {Name: weaponHoming, Cooldown: 20, Damage: 2, Fire: fireHoming},
+	weaponRail:   {Name: weaponRail, Cooldown: 30, Damage: 3, Fire: fireRail},
+	weaponCharge: {Name: weaponCharge, Cooldown: 10, Damage: 1, Fire: fireChargeShot},
+}
+
+// 武器切り替えアイテムの種類（Pickup.Item に設定される）
+const (
+	itemWeaponSpread = "weaponSpread"
+	itemWeaponLaser  = "weaponLaser"
+	itemWeaponHoming = "weaponHoming"
+	itemWeaponRail   = "weaponRail"
+	itemWeaponCharge = "weaponCharge"
+)
+
+// 武器切り替えアイテム（キー：アイテム種類、値：武器名）
+var weaponPickups = map[string]string{
+	itemWeaponSpread: weaponSpread,
+	itemWeaponLaser:  weaponLaser,
+	itemWeaponHoming: weaponHoming,
+	itemWeaponRail:   weaponRail,
+	itemWeaponCharge: weaponCharge,
+}
+
+/**
+ * プレイヤーの装備武器で弾を発射する
+ * 連射間隔に達していない場合は何もしない
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {*Player} player - 弾を発射するプレイヤーへのポインタ
+ */
+func createBullet(gameRoom *GameRoom, player *Player) {
+	gameRoom.Mutex.Lock()
+	defer gameRoom.Mutex.Unlock()
+
+	if gameRoom.GameState != "playing" || player.Health.Current <= 0 {
+		return
+	}
+	def, ok := weapons[player.Weapon.Name]
+	if !ok {
+		def = weapons[defaultWeapon]
+	}
+	if player.Charge < def.Cooldown {
+		return
+	}
+	def.Fire(gameRoom, player, def)
+	player.Charge = 0
+}
+
+/**
+ * 武器のチャージを1ティック進める（前回の発射からの経過ティック数）
+ * @param {*Weapon} w - 武器コンポーネント
+ */
+func tickWeapon(w *Weapon) {
+	if w.Charge < maxChargeTicks {
+		w.Charge++
+	}
+}
+
+/**
+ * プレイヤー弾の生成
+ * プレイヤーの上端中央を基準に弾を追加する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Player} p - 発射したプレイヤー
+ * @param {string} kind - 武器名（弾の挙動と描画に使用）
+ * @param {float64} offset - 中央からのX方向オフセット
+ * @param {float64} vx - X方向の速度
+ * @param {float64} vy - Y方向の速度
+ * @param {int} width - 弾の幅
+ * @param {int} height - 弾の高さ
+ * @param {int} damage - 命中時のダメージ
+ * @param {int} pierce - 貫通回数（何体目まで突き抜けるか）
+ * @returns {*Entity, *ComponentStore} - 生成された弾と、そのコンポーネントの実体
+ */
+func spawnPlayerBullet(gameRoom *GameRoom, p *Player, kind string, offset, vx, vy float64, width, height, damage, pierce int) (*Entity, *ComponentStore) {
+	b, c := gameRoom.entities.Spawn()
+	b.Type = "bullet"
+	b.Kind = kind
+	b.Transform = transformAt(p.X+float64(p.Width)/2-float64(width)/2+offset, p.Y, width, height)
+	b.Velocity = c.Velocity(Velocity{VelocityX: vx, VelocityY: vy})
+	b.Collider = c.Collider(Collider{Damage: damage, Pierce: pierce, Owner: p.ID})
+	b.Lifetime = c.Lifetime(Lifetime{})
+	setLayer(b, layerPlayerShot)
+	if mode := roomMode(gameRoom); mode.PvP || gameRoom.Rules.FriendlyFire {
+		b.Mask |= layerPlayer
+	} else if mode.Teams {
+		b.Mask |= layerPickup // アイテムを壊して相手の陣地に敵を送る
+	}
+	initProjectile(b)
+	gameRoom.Bullets[b.ID] = b
+	return b, c
+}
+
+/**
+ * 拡散弾: FirePower の数だけ左右に拡散
+ */
+func fireSpreadShot(gameRoom *GameRoom, p *Player, def WeaponDefinition) {
+	for i := 0; i < p.FirePower; i++ {
+		// 簡易的に左右に拡散させるオフセット
+		offset := float64(i-(p.FirePower-1)/2) * 5
+		spawnPlayerBullet(gameRoom, p, def.Name, offset, offset*0.2, -6, 5, 10, def.Damage, 0)
+	}
+}
+
+/**
+ * レーザー: 高速で細長い弾。レベルで太さ・ダメージ・貫通数が増加
+ */
+func fireLaser(gameRoom *GameRoom, p *Player, def WeaponDefinition) {
+	spawnPlayerBullet(gameRoom, p, def.Name, 0, 0, -14, 2+p.FirePower, 30, def.Damage+p.FirePower/2, p.FirePower/2)
+}
+
+/**
+ * ホーミング: レベル数のミサイルを発射し、近くの敵を追尾
+ */
+func fireHoming(gameRoom *GameRoom, p *Player, def WeaponDefinition) {
+	for i := 0; i < p.FirePower; i++ {
+		offset := float64(i-(p.FirePower-1)/2) * 12
+		b, c := spawnPlayerBullet(gameRoom, p, def.Name, offset, offset*0.1, -4, 6, 6, def.Damage, 0)
+		b.AI = c.AI(AI{Update: homingAI})
+	}
+}
+
+/**
+ * レール: 超高速で敵を貫通する一撃。レベルでダメージと貫通数が増加
+ */
+func fireRail(gameRoom *GameRoom, p *Player, def WeaponDefinition) {
+	spawnPlayerBullet(gameRoom, p, def.Name, 0, 0, -20, 4, 50, def.Damage*p.FirePower, 3+p.FirePower)
+}
+
+/**
+ * チャージショット: 前回発射からの経過時間に応じて大きく強く、貫通する弾
+ */
+func fireChargeShot(gameRoom *GameRoom, p *Player, def WeaponDefinition) {
+	charge := float64(p.Charge) / maxChargeTicks // 0〜1
+	size := 6 + int(charge*18)
+	damage := def.Damage + int(charge*float64(2*p.FirePower))
+	// フルチャージに近いほど貫通
+	pierce := int(charge * 2)
+	spawnPlayerBullet(gameRoom, p, def.Name, 0, 0, -7, size, size, damage, pierce)
+}
+
+/**
+ * ホーミング弾のAI: 最も近い標的へ向けて少しずつ旋回する
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} b - 弾
+ */
+func homingAI(gameRoom *GameRoom, b *Entity) {
+	target := nearestTarget(gameRoom, b)
+	if target == nil {
+		return
+	}
+	dx := target.X + float64(target.Width)/2 - (b.X + float64(b.Width)/2)
+	dy := target.Y + float64(target.Height)/2 - (b.Y + float64(b.Height)/2)
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	// 現在の速度を目標方向へ少しずつ向ける
+	vx := b.VelocityX + (dx/length*homingSpeed-b.VelocityX)*homingTurnRate
+	vy := b.VelocityY + (dy/length*homingSpeed-b.VelocityY)*homingTurnRate
+	speed := math.Hypot(vx, vy)
+	if speed == 0 {
+		return
+	}
+	b.VelocityX = vx / speed * homingSpeed
+	b.VelocityY = vy / speed * homingSpeed
+}
+
+/**
+ * 弾から最も近い敵またはボスを探す（PvPのモードでは他の生存中のプレイヤーも対象）
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ（ロック済みであること）
+ * @param {*Entity} b - 弾
+ * @returns {*Entity} - 最も近い標的（いなければnil）
+ */
+func nearestTarget(gameRoom *GameRoom, b *Entity) *Entity {
+	var target *Entity
+	best := math.MaxFloat64
+	consider := func(e *Entity) {
+		d := math.Hypot(e.X-b.X, e.Y-b.Y)
+		if d < best {
+			best = d
+			target = e
+		}
+	}
+	for _, e := range gameRoom.Enemies {
+		consider(e)
+	}
+	for _, boss := range gameRoom.Bosses {
+		consider(boss)
+	}
+	if roomMode(gameRoom).PvP {
+		for _, p := range gameRoom.Players {
+			if p.ID != b.Owner && isAlive(p) {
+				consider(&p.Entity)
+			}
+		}
+	}
+	return target
+}
@@ -0,0 +1,144 @@
+/**
+ * @file weapons_test.go
+ * @description プレイヤーの武器システムのテスト
+ *
+ * 概要:
+ * - 連射間隔に達するまで発射されず、発射でチャージがリセットされることを確認する
+ * - 武器レベルで弾数・ダメージ・貫通数が増えることを確認する
+ * - 切り替えアイテムで武器が変わり、武器レベルは引き継がれることを確認する
+ * - チャージショットが溜めるほど大きく強くなることを確認する
+ * - ホーミング弾が敵の方向へ旋回することを確認する
+ */
+
+package main
+
+import "testing"
+
+/**
+ * 連射間隔を満たした状態で1回発射し、生成された弾を返す
+ * @param {*GameRoom} gameRoom - ゲームルームへのポインタ
+ * @param {*Player} p - プレイヤー
+ * @param {int} charge - 発射前のチャージ（前回の発射からの経過ティック数）
+ * @returns {[]*Entity} - 生成された弾
+ */
+func fireOnce(gameRoom *GameRoom, p *Player, charge int) []*Entity {
+	before := make(map[EntityID]bool, len(gameRoom.Bullets))
+	for id := range gameRoom.Bullets {
+		before[id] = true
+	}
+	p.Charge = charge
+	createBullet(gameRoom, p)
+	var fired []*Entity
+	for id, b := range gameRoom.Bullets {
+		if !before[id] {
+			fired = append(fired, b)
+		}
+	}
+	return fired
+}
+
+func TestWeaponCooldown(t *testing.T) {
+	for name, def := range weapons {
+		t.Run(name, func(t *testing.T) {
+			gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+			p := testPlayer(gameRoom, "p1", 400, 500)
+			p.Weapon.Name = name
+
+			if fired := fireOnce(gameRoom, p, def.Cooldown-1); len(fired) != 0 {
+				t.Fatalf("連射間隔の前に %d 発撃てた", len(fired))
+			}
+			if fired := fireOnce(gameRoom, p, def.Cooldown); len(fired) == 0 {
+				t.Fatal("連射間隔に達しても撃てない")
+			}
+			if p.Charge != 0 {
+				t.Errorf("発射後のチャージ %d, want 0", p.Charge)
+			}
+		})
+	}
+}
+
+func TestWeaponLevels(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+
+	for level := 1; level <= maxFirePower; level++ {
+		p.FirePower = level
+
+		p.Weapon.Name = weaponSpread
+		if fired := fireOnce(gameRoom, p, maxChargeTicks); len(fired) != level {
+			t.Errorf("拡散 レベル%d: %d 発, want %d", level, len(fired), level)
+		}
+
+		p.Weapon.Name = weaponRail
+		fired := fireOnce(gameRoom, p, maxChargeTicks)
+		if len(fired) != 1 {
+			t.Fatalf("レール レベル%d: %d 発", level, len(fired))
+		}
+		if b := fired[0]; b.Damage != 3*level || b.Pierce != 3+level {
+			t.Errorf("レール レベル%d: ダメージ %d・貫通 %d, want %d・%d", level, b.Damage, b.Pierce, 3*level, 3+level)
+		}
+	}
+}
+
+func TestWeaponPickupSwitches(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	p.FirePower = 3
+
+	for item, weapon := range weaponPickups {
+		applyItem(gameRoom, p, item)
+		if p.Weapon.Name != weapon {
+			t.Errorf("%s を取得して武器 %q, want %q", item, p.Weapon.Name, weapon)
+		}
+		if p.FirePower != 3 {
+			t.Errorf("%s で武器レベルが変わった: %d", item, p.FirePower)
+		}
+		fired := fireOnce(gameRoom, p, maxChargeTicks)
+		if len(fired) == 0 || fired[0].Kind != weapon {
+			t.Errorf("%s の弾が撃てない", weapon)
+		}
+	}
+}
+
+func TestChargeShotGrows(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	p.Weapon.Name = weaponCharge
+
+	quick := fireOnce(gameRoom, p, weapons[weaponCharge].Cooldown)[0]
+	full := fireOnce(gameRoom, p, maxChargeTicks)[0]
+	if full.Width <= quick.Width || full.Damage <= quick.Damage {
+		t.Errorf("フルチャージが強くならない: 幅 %d→%d・ダメージ %d→%d", quick.Width, full.Width, quick.Damage, full.Damage)
+	}
+	if quick.Pierce != 0 || full.Pierce == 0 {
+		t.Errorf("貫通 %d→%d, want 0→1以上", quick.Pierce, full.Pierce)
+	}
+
+	w := &Weapon{Charge: maxChargeTicks}
+	tickWeapon(w)
+	if w.Charge != maxChargeTicks {
+		t.Errorf("チャージが上限を超えた: %d", w.Charge)
+	}
+}
+
+func TestHomingTurnsTowardEnemy(t *testing.T) {
+	gameRoom := newGameRoom(defaultDifficulty, modeCoop, defaultRules)
+	p := testPlayer(gameRoom, "p1", 400, 500)
+	p.Weapon.Name = weaponHoming
+
+	e, c := gameRoom.entities.Spawn()
+	e.Type = "enemy"
+	e.Transform = transformAt(700, 400, 20, 20)
+	e.Velocity = c.Velocity(Velocity{})
+	e.Collider = c.Collider(Collider{})
+	setLayer(e, layerEnemy)
+	gameRoom.Enemies[e.ID] = e
+
+	b := fireOnce(gameRoom, p, maxChargeTicks)[0]
+	for i := 0; i < 10; i++ {
+		homingAI(gameRoom, b)
+	}
+	if b.VelocityX <= 0 {
+		t.Errorf("右の敵へ旋回しない: vx=%v", b.VelocityX)
+	}
+}